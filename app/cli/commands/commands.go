@@ -1,4 +1,4 @@
-// Package commands provides high-level processing of a WordPress SitesList.
+// Package commands provides high-level processing of a SitesList of WordPress (and other source) sites.
 package commands
 
 import (
@@ -8,21 +8,27 @@ import (
 	"time"
 
 	"github.com/ardanlabs/conf"
+	"github.com/pkg/errors"
 	"github.com/sempernow/kit/types/convert"
 	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
+	"github.com/sempernow/uqc/client/source"
 	"github.com/sempernow/uqc/client/wordpress"
 )
 
 const (
-	SUFFIX_POSTS = "_posts.json"
-	SUFFIX_MSGS  = "_msgs.json"
+	SUFFIX_MSGS = "_msgs.json"
 )
 
 // UpsertChannels of sites list with values therein.
 func UpsertChannels(env *client.Env) {
-	sites := wordpress.GetSitesList(env)
+	sites := wordpress.GetSitesList(env, DescribeSite)
 	env.Client.Pass = env.SitesPass
 	for _, site := range sites {
+		if !site.Enabled() {
+			env.Logger.Printf("INFO : SKIP @ %s : disabled\n", site.UserHandle)
+			continue
+		}
 		wp := wordpress.NewWordPress(env, &site)
 		env.Client.User = site.UserHandle
 		tkn := wp.GetTkn()
@@ -46,14 +52,36 @@ func UpsertChannels(env *client.Env) {
 	}
 }
 
-// UpdateUsers of sites list with values therein.
+// DescribeSite gets the dynamic fields of a (valid, enabled) site, by reference, per its source; see source.Source.
+func DescribeSite(env *client.Env, site *mirror.Site) {
+	if site.Error != "" {
+		env.Logger.Printf("ERR : sites list : row %d : %s\n", site.Row, site.Error)
+		return
+	}
+	if !site.Enabled() {
+		return
+	}
+	src, err := source.New(env, site)
+	if err != nil {
+		site.Error = err.Error()
+		return
+	}
+	src.Describe()
+}
+
+// UpdateUsers of sites list with values therein, each site described anew (see DescribeSite).
+// Sites of no name (e.g., unavailable) are not updated.
 func UpdateUsers(env *client.Env) {
-	sites := wordpress.GetSitesList(env)
+	sites := wordpress.GetSitesList(env, DescribeSite)
 
 	// All sites mirrored hereby share common password
 	env.Client.Pass = env.SitesPass
 
 	for _, site := range sites {
+		if !site.Enabled() {
+			env.Logger.Printf("INFO : SKIP @ %s : disabled\n", site.UserHandle)
+			continue
+		}
 		wp := wordpress.NewWordPress(env, &site)
 		env.Client.User = site.UserHandle
 
@@ -63,19 +91,18 @@ func UpdateUsers(env *client.Env) {
 			continue
 		}
 
+		DescribeSite(env, &site)
+		if site.Name == "" {
+			env.Logger.Printf("WARN : SKIP @ %s : site name UNAVAILABLE : %s\n", site.UserHandle, site.Error)
+			continue
+		}
+
 		// Get/Set avatar and banner
 
 		var (
-			avatar = "-avatar.webp"
+			avatar = siteAvatar(env, &site)
 			banner = "-banner.webp"
 		)
-		if _, err := os.ReadFile(
-			filepath.Join(env.Assets, "media", "avatars", (site.UserHandle + avatar)),
-		); err != nil {
-			avatar = "wordpress" + avatar
-		} else {
-			avatar = site.UserHandle + avatar
-		}
 		if _, err := os.ReadFile(
 			filepath.Join(env.Assets, "media", "banners", (site.UserHandle + banner)),
 		); err != nil {
@@ -109,10 +136,55 @@ func UpdateUsers(env *client.Env) {
 	}
 }
 
+// Types of image of a site icon fetched as its avatar (see siteAvatar), and the file extension of each.
+var avatarTypes = map[string]string{
+	"image/webp":               ".webp",
+	"image/png":                ".png",
+	"image/jpeg":               ".jpg",
+	"image/gif":                ".gif",
+	"image/svg+xml":            ".svg",
+	"image/x-icon":             ".ico",
+	"image/vnd.microsoft.icon": ".ico",
+}
+
+// siteAvatar returns the file name of the avatar of a site at assets (media/avatars); that curated (<handle>-avatar.webp),
+// else that of its icon (Site.Icon) per its sites-list record, fetched thereto once (<handle>-avatar.<ext>), else the default.
+func siteAvatar(env *client.Env, site *mirror.Site) string {
+	dir := filepath.Join(env.Assets, "media", "avatars")
+	fname := site.UserHandle + "-avatar"
+	if _, err := os.Stat(filepath.Join(dir, fname+".webp")); err == nil {
+		return fname + ".webp"
+	}
+	if site.Icon == "" {
+		return "wordpress-avatar.webp"
+	}
+	for _, ext := range avatarTypes {
+		if _, err := os.Stat(filepath.Join(dir, fname+ext)); err == nil {
+			return fname + ext
+		}
+	}
+	rsp := env.Get(site.Icon, client.IMG)
+	site.Pause()
+	ext := avatarTypes[strings.TrimSpace(strings.Split(rsp.Header.Get("Content-Type"), ";")[0])]
+	if rsp.Error != "" || ext == "" || rsp.Body == "" {
+		env.Logger.Printf("WARN : avatar @ %s : icon UNAVAILABLE : %s : HTTP %d\n", site.UserHandle, site.Icon, rsp.Code)
+		return "wordpress-avatar.webp"
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		env.Logger.Printf("ERR : avatar @ %s : %s\n", site.UserHandle, err.Error())
+		return "wordpress-avatar.webp"
+	}
+	if err := os.WriteFile(filepath.Join(dir, fname+ext), []byte(rsp.Body), 0664); err != nil {
+		env.Logger.Printf("ERR : avatar @ %s : %s\n", site.UserHandle, err.Error())
+		return "wordpress-avatar.webp"
+	}
+	return fname + ext
+}
+
 // PurgeCacheTkns removes token cache.
 func PurgeCacheTkns(env *client.Env) {
 	env.Logger.Printf("INFO : PurgeCacheTkns ("+client.CacheKeyTknPrefix+"*) @ %s\n", env.Cache)
-	sites := wordpress.GetSitesList(env)
+	sites := wordpress.GetSitesList(env, DescribeSite)
 	for _, site := range sites {
 		fname := client.CacheKeyTknPrefix + site.UserHandle
 		if err := os.Remove(filepath.Join(env.Cache, fname)); err != nil {
@@ -122,18 +194,19 @@ func PurgeCacheTkns(env *client.Env) {
 	}
 }
 
-// PurgeCachePosts removes posts and messages cache.
+// PurgeCachePosts removes posts (of each type), comments and messages cache.
 func PurgeCachePosts(env *client.Env) {
 	env.Logger.Printf("INFO: PurgeCachePosts @ %s\n", env.Cache)
-	sites := wordpress.GetSitesList(env)
+	sites := wordpress.GetSitesList(env, DescribeSite)
 	for _, site := range sites {
-		domain := strings.Split(site.HostURL, "//")[1]
-		fname := domain + SUFFIX_POSTS
-		if err := os.Remove(filepath.Join(env.Cache, fname)); err != nil {
-		} else {
-			env.Logger.Printf("INFO : DEL @ %s\n", fname)
+		wp := wordpress.NewWordPress(env, &site)
+		for _, fname := range append(wp.PostsCacheKeys(), wp.CommentsCacheKeys()...) {
+			if err := os.Remove(filepath.Join(env.Cache, fname)); err != nil {
+			} else {
+				env.Logger.Printf("INFO : DEL @ %s\n", fname)
+			}
 		}
-		fname = domain + SUFFIX_MSGS
+		fname := domain(&site) + SUFFIX_MSGS
 		if err := os.Remove(filepath.Join(env.Cache, fname)); err != nil {
 		} else {
 			env.Logger.Printf("INFO : DEL @ %s\n", fname)
@@ -141,17 +214,19 @@ func PurgeCachePosts(env *client.Env) {
 	}
 }
 
-// UpsertPosts converts []Post into []client.Message of all sites in []Site list,
+// UpsertPosts converts the items of all sites in []Site list into []client.Message, per source of each,
 // upserting the Uqrate messages to their associated channel (mirror) per site.
+// Only items updated since the checkpoint of a site are upserted;
+// only of those sites enabled, and due per their schedule (see source.Due).
 func UpsertPosts(env *client.Env) {
 	PurgeCacheTkns(env)
 	PurgeCachePosts(env)
-	sites := wordpress.GetSitesList(env)
+	sites := wordpress.GetSitesList(env, DescribeSite)
 	env.Channel.Slug = "Mirror"
 	env.Client.Pass = env.SitesPass
 	var (
-		wp   *wordpress.WP
-		msgs []client.Message
+		upserted int
+		skipped  = map[string]int{}
 	)
 
 	// Process each site in sites list
@@ -161,37 +236,184 @@ func UpsertPosts(env *client.Env) {
 			// @ CSV header (first row)
 			continue
 		}
+		if !site.Enabled() {
+			env.Logger.Printf("INFO : SKIP Site #%d : %s : disabled\n", i, site.UserHandle)
+			continue
+		}
+		now := time.Now()
+		if !source.Due(env, &site, now) {
+			env.Logger.Printf("INFO : SKIP Site #%d : %s : not due (schedule %s)\n", i, site.UserHandle, time.Duration(site.Schedule))
+			continue
+		}
 		env.Logger.Printf("INFO : Site #%d : %s\n", i, site.UserHandle)
+		upserted += upsertSite(env, &site, true, skipped)
+		if err := source.SetLastRun(env, &site, now); err != nil {
+			env.Logger.Printf("ERR : SetLastRun @ %s : %s\n", site.UserHandle, err.Error())
+		}
+	}
+
+	// Run summary
+	env.Logger.Printf("INFO : UpsertPosts : sites: %d : upserted: %d\n", len(sites), upserted)
+	for reason, n := range skipped {
+		env.Logger.Printf("INFO : UpsertPosts : skipped: %d : %s\n", n, reason)
+	}
+}
+
+// MirrorFeed upserts the items of a single JSON Feed (url) into the channel (mirror)
+// of a site (handle) of the sites list, regardless of the source declared thereof.
+// All items of the feed are upserted; the checkpoint of the site is neither read nor advanced.
+func MirrorFeed(env *client.Env, url, handle string) error {
+	if url == "" || handle == "" {
+		return errors.New("usage : mirrorfeed $url $handle")
+	}
+	return upsertOne(env, handle, func(site *mirror.Site) {
+		site.Source = source.JSONFeed
+		site.FeedURL = url
+	})
+}
+
+// PublishMarkdown upserts the Markdown files of a (local) directory (dir) into the channel
+// of a site (handle) of the sites list, regardless of the source declared thereof.
+// Only files whose content changed since last upserted are upserted.
+func PublishMarkdown(env *client.Env, dir, handle string) error {
+	if dir == "" || handle == "" {
+		return errors.New("usage : publishmd $dir $handle")
+	}
+	return upsertOne(env, handle, func(site *mirror.Site) {
+		site.Source = source.Markdown
+		site.Dir = dir
+	})
+}
 
-		wp = wordpress.NewWordPress(env, &site)
-		wp.SitePosts()
-		if len(wp.Site.Posts) == 0 {
-			env.Logger.Printf("WARN : NO SitePosts @ %s : %s\n", site.UserHandle, wp.Site.Error)
+// upsertOne upserts the items of a site (handle) of the sites list, per its record as modified (mod);
+// the checkpoint of the site is neither read nor advanced.
+func upsertOne(env *client.Env, handle string, mod func(*mirror.Site)) error {
+	PurgeCacheTkns(env)
+	env.Channel.Slug = "Mirror"
+	env.Client.Pass = env.SitesPass
+	for _, site := range wordpress.GetSitesList(env, DescribeSite) {
+		if site.UserHandle != handle {
 			continue
 		}
+		mod(&site)
+		skipped := map[string]int{}
+		n := upsertSite(env, &site, false, skipped)
+		env.Logger.Printf("INFO : %s @ %s : upserted: %d\n", site.Source, handle, n)
+		for reason, n := range skipped {
+			env.Logger.Printf("INFO : %s @ %s : skipped: %d : %s\n", site.Source, handle, n, reason)
+		}
+		if site.Error != "" {
+			return errors.New(site.Error)
+		}
+		return nil
+	}
+	return errors.Errorf("site NOT FOUND : %s", handle)
+}
 
-		msgs = wp.PostsToMsgs()
-		if len(msgs) == 0 {
-			env.Logger.Printf("WARN : NO PostsToMsgs @ %s\n", site.UserHandle)
-			continue
+// upsertSite upserts the items of a site, per its source, returning the number upserted;
+// only those updated since its checkpoint if checkpointed. Skipped items are counted per reason (skipped).
+func upsertSite(env *client.Env, site *mirror.Site, checkpointed bool, skipped map[string]int) int {
+	upserted := 0
+
+	src, err := source.New(env, site)
+	if err != nil {
+		env.Logger.Printf("ERR : source @ %s : %s\n", site.UserHandle, err.Error())
+		return 0
+	}
+	since := time.Time{}
+	if checkpointed {
+		since = source.Checkpoint(env, site)
+	}
+	src.Fetch(since)
+	for _, skip := range site.Skipped {
+		env.Logger.Printf("INFO : SKIP @ %s : post %d : %s : %s\n", site.UserHandle, skip.ID, skip.Reason, skip.Link)
+		skipped[skip.Reason]++
+	}
+
+	msgs := src.Messages()
+	for i := range msgs {
+		site.ApplyTagPolicy(&msgs[i])
+	}
+	if len(msgs) == 0 {
+		if site.Error != "" {
+			env.Logger.Printf("WARN : NO Messages @ %s : %s\n", site.UserHandle, site.Error)
+		} else {
+			env.Logger.Printf("INFO : NO Messages @ %s : none since %v\n", site.UserHandle, since)
 		}
+		return 0
+	}
 
-		// Get access token for upsert of this user's channel
-		wp.Env.Client.User = site.UserHandle
-		tkn := wp.GetTkn()
-		if tkn == "" {
+	// Get access token for upsert of this user's channel
+	env.Client.User = site.UserHandle
+	tkn := wordpress.NewWordPress(env, site).GetTkn()
+	if tkn == "" {
+		return 0
+	}
+
+	// The checkpoint stops short of the earliest failed; see source.Advance.
+	failed := time.Time{}
+	done := []client.Message{}
+	for _, msg := range msgs {
+		id := msg.ID // Cleared by the upsert
+		rsp := env.UpsertMsgByTkn(&msg)
+		env.Logger.Printf("INFO : UpsertMsgByTkn @ %s : HTTP %d\n", site.UserHandle, rsp.Code)
+		if rsp.Error == "" {
+			upserted++
+			msg.ID = id
+			done = append(done, msg)
 			continue
 		}
-
-		for _, msg := range msgs {
-			rsp := env.UpsertMsgByTkn(&msg)
-			env.Logger.Printf("INFO : UpsertMsgByTkn @ %s : HTTP %d\n", site.UserHandle, rsp.Code)
+		if failed.IsZero() || msg.DateUpdate.Before(failed) {
+			failed = msg.DateUpdate
 		}
+	}
+	latest := source.Advance(site, since, done, failed)
+	if c, ok := src.(source.Committer); ok {
+		c.Commit(done)
+	}
+	if checkpointed && latest.After(since) {
+		if err := source.SetCheckpoint(env, site, latest); err != nil {
+			env.Logger.Printf("ERR : SetCheckpoint @ %s : %s\n", site.UserHandle, err.Error())
+		}
+	}
+
+	if err := env.SetCache(domain(site)+SUFFIX_MSGS, convert.Stringify(msgs)); err != nil {
+		env.Logger.Printf("ERR : SetCache @ %s : *"+SUFFIX_MSGS+" : %s\n", site.UserHandle, err.Error())
+	}
+	return upserted
+}
 
-		domain := strings.Split(site.HostURL, "//")[1]
-		if err := env.SetCache(domain+SUFFIX_MSGS, convert.Stringify(msgs)); err != nil {
-			env.Logger.Printf("ERR : SetCache @ %s : *"+SUFFIX_MSGS+" : %s\n", site.UserHandle, err.Error())
+// domain returns that of the site, else its user handle if the site has none (e.g., a local source).
+func domain(site *mirror.Site) string {
+	if ss := strings.Split(site.HostURL, "//"); len(ss) > 1 {
+		return strings.Split(ss[1], "/")[0]
+	}
+	return site.UserHandle
+}
+
+// MigrateIDs reconciles messages mirrored under the legacy (URI) identity
+// of each site declaring another identity strategy, by recording their aliases.
+func MigrateIDs(env *client.Env) {
+	sites := wordpress.GetSitesList(env, DescribeSite)
+	for _, site := range sites {
+		if site.Identity == "" || site.Identity == mirror.IdentityURI {
+			continue
 		}
+		wp := wordpress.NewWordPress(env, &site)
+		posts, err := wp.AllPosts()
+		if err != nil {
+			env.Logger.Printf("ERR : AllPosts @ %s : %s\n", site.UserHandle, err.Error())
+			continue
+		}
+		wp.Posts = posts
+		n := wp.MigrateIDs()
+		if err := wp.SaveAliases(); err != nil {
+			env.Logger.Printf("ERR : SaveAliases @ %s : %s\n", site.UserHandle, err.Error())
+			continue
+		}
+		env.Logger.Printf("INFO : MigrateIDs @ %s : identity: %s : aliases added: %d (total: %d)\n",
+			site.UserHandle, site.Identity, n, len(wp.Aliases),
+		)
 	}
 }
 
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
+	"github.com/sempernow/uqc/client/wordpress"
+)
+
+// DiffSitesList prints (to STDOUT) the diff of the current sites list (JSON) against one made anew
+// (see wordpress.MakeSitesList, else wordpress.MakeSitesListFromService), sans caching the latter.
+func DiffSitesList(env *client.Env, fromService bool) error {
+	old, err := wordpress.ReadSitesListJSON(env)
+	if err != nil {
+		return err
+	}
+	var cur []mirror.Site
+	if fromService {
+		if cur, err = wordpress.MakeSitesListFromService(env, DescribeSite); err != nil {
+			return err
+		}
+	} else {
+		cur = wordpress.MakeSitesList(env, DescribeSite)
+	}
+	PrintSitesDiff(old, cur)
+	return nil
+}
+
+// PrintSitesDiff prints (to STDOUT) the diff of two sites lists (see wordpress.DiffSitesList), and its summary;
+// sites whose Status.Code or Error flipped are highlighted ("!!").
+func PrintSitesDiff(old, cur []mirror.Site) {
+	added, removed, changed, flipped := 0, 0, 0, 0
+	for _, d := range wordpress.DiffSitesList(old, cur) {
+		switch {
+		case d.Added:
+			added++
+			fmt.Printf("   %-24s : ADDED\n", d.Handle)
+			continue
+		case d.Removed:
+			removed++
+			fmt.Printf("   %-24s : REMOVED\n", d.Handle)
+			continue
+		}
+		changed++
+		mark := "  "
+		if d.Flipped {
+			flipped++
+			mark = "!!"
+		}
+		for _, c := range d.Changes {
+			fmt.Printf("%s %-24s : %s : %q => %q\n", mark, d.Handle, c.Field, c.Old, c.New)
+		}
+	}
+	fmt.Printf("\nsites list : sites: %d (was %d) : added: %d : removed: %d : changed: %d : status flipped: %d\n",
+		len(cur), len(old), added, removed, changed, flipped,
+	)
+}
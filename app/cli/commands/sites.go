@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sempernow/kit/types/convert"
+	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
+	"github.com/sempernow/uqc/client/wordpress"
+)
+
+// SiteAdd adds a site, of its fields declared as key=value pairs (see mirror.Site.SetField), to the sites list;
+// to both its file (see wordpress.EditSitesList) and JSON (see wordpress.SaveSitesList),
+// having validated it, whereby it is described alone (see ValidateSites).
+// If the JSON does not exist, only the file is edited; the JSON is made thereof per the next run. E.g.,
+//
+//	site add user_handle=foo slug=bar host_url=https://foo.bar owner_id=$uid chn_id=$cid source=feed
+func SiteAdd(env *client.Env, fields ...string) error {
+	site := mirror.Site{}
+	for _, kv := range fields {
+		ss := strings.SplitN(kv, "=", 2)
+		if len(ss) != 2 {
+			return errors.Errorf("malformed field : %s", kv)
+		}
+		if err := site.SetField(ss[0], ss[1]); err != nil {
+			return err
+		}
+	}
+	sites, err := wordpress.ReadSitesListJSON(env)
+	if err != nil {
+		return err
+	}
+	if err := duplicateOf(sites, site); err != nil {
+		return err
+	}
+	described := []mirror.Site{site} // Per validation; the file keeps the fields declared (site).
+	r := ValidateSites(env, described)[0]
+	for _, w := range r.Warnings {
+		fmt.Printf("%s : WARN : %s\n", site.UserHandle, w)
+	}
+	if len(r.Errors) > 0 {
+		return errors.Errorf("site INVALID : %s", strings.Join(r.Errors, " : "))
+	}
+
+	if err := wordpress.EditSitesList(env, func(ss []mirror.Site) ([]mirror.Site, error) {
+		if err := duplicateOf(ss, site); err != nil {
+			return ss, err
+		}
+		return append(ss, site), nil
+	}); err != nil {
+		return err
+	}
+	if len(sites) == 0 {
+		env.Logger.Printf("INFO : sites list (%s) NOT FOUND : made per the next run\n", env.SitesListJSON)
+		return nil
+	}
+	return wordpress.SaveSitesList(env, append(sites, described[0]))
+}
+
+// duplicateOf returns error if a site of sites is of the handle, slug or channel of site.
+func duplicateOf(sites []mirror.Site, site mirror.Site) error {
+	for _, s := range sites {
+		switch {
+		case site.UserHandle != "" && strings.EqualFold(s.UserHandle, site.UserHandle):
+			return errors.Errorf("duplicate handle : %s", site.UserHandle)
+		case site.ChnSlug != "" && strings.EqualFold(s.ChnSlug, site.ChnSlug):
+			return errors.Errorf("duplicate slug : %s", site.ChnSlug)
+		case site.ChnID != "" && strings.EqualFold(s.ChnID, site.ChnID):
+			return errors.Errorf("duplicate chn_id : %s", site.ChnID)
+		}
+	}
+	return nil
+}
+
+// SiteRemove removes a site (per handle) from the sites list; from both its file and JSON.
+func SiteRemove(env *client.Env, handle string) error {
+	sites, err := readSites(env)
+	if err != nil {
+		return err
+	}
+	i := indexOf(sites, handle)
+	if i < 0 {
+		return errors.Errorf("site NOT FOUND : %s", handle)
+	}
+	if err := wordpress.EditSitesList(env, func(ss []mirror.Site) ([]mirror.Site, error) {
+		j := indexOf(ss, handle)
+		if j < 0 {
+			env.Logger.Printf("WARN : site NOT FOUND in sites list file : %s\n", handle)
+			return ss, nil
+		}
+		return append(ss[:j], ss[j+1:]...), nil
+	}); err != nil {
+		return err
+	}
+	return wordpress.SaveSitesList(env, append(sites[:i], sites[i+1:]...))
+}
+
+// SiteEnable enables (else disables) a site (per handle) of the sites list; see option "enabled".
+// The dynamic fields of a site enabled are got anew; see DescribeSite.
+func SiteEnable(env *client.Env, handle string, enable bool) error {
+	sites, err := readSites(env)
+	if err != nil {
+		return err
+	}
+	i := indexOf(sites, handle)
+	if i < 0 {
+		return errors.Errorf("site NOT FOUND : %s", handle)
+	}
+	if err := wordpress.EditSitesList(env, func(ss []mirror.Site) ([]mirror.Site, error) {
+		j := indexOf(ss, handle)
+		if j < 0 {
+			return ss, errors.Errorf("site NOT FOUND in sites list file : %s", handle)
+		}
+		ss[j].Disabled = !enable
+		return ss, nil
+	}); err != nil {
+		return err
+	}
+	site := &sites[i]
+	site.Disabled = !enable
+	if enable {
+		site.Error = ""
+		DescribeSite(env, site)
+	}
+	return wordpress.SaveSitesList(env, sites)
+}
+
+// SiteShow prints (to STDOUT) the record (JSON) of a site (per handle) of the sites list.
+func SiteShow(env *client.Env, handle string) error {
+	sites, err := readSites(env)
+	if err != nil {
+		return err
+	}
+	i := indexOf(sites, handle)
+	if i < 0 {
+		return errors.Errorf("site NOT FOUND : %s", handle)
+	}
+	fmt.Println(convert.PrettyPrint(sites[i]))
+	return nil
+}
+
+// readSites reads the sites list (JSON) sans making it anew; see wordpress.ReadSitesListJSON.
+// Returns error if that is empty or not found.
+func readSites(env *client.Env) ([]mirror.Site, error) {
+	sites, err := wordpress.ReadSitesListJSON(env)
+	if err != nil {
+		return sites, err
+	}
+	if len(sites) == 0 {
+		return sites, errors.Errorf("sites list (%s) EMPTY or NOT FOUND", env.SitesListJSON)
+	}
+	return sites, nil
+}
+
+// indexOf returns that of the site of a handle (case insensitive) in sites; -1 if none.
+func indexOf(sites []mirror.Site, handle string) int {
+	for i, site := range sites {
+		if strings.EqualFold(site.UserHandle, handle) {
+			return i
+		}
+	}
+	return -1
+}
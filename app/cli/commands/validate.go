@@ -0,0 +1,224 @@
+package commands
+
+import (
+	"fmt"
+	neturl "net/url"
+	"os"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
+	"github.com/sempernow/uqc/client/source"
+	"github.com/sempernow/uqc/client/wordpress"
+)
+
+// Report is that of the validation of a record (row) of the sites list.
+type Report struct {
+	Row      int      `json:"row"`
+	Handle   string   `json:"handle,omitempty"`
+	Errors   []string `json:"errors,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+func (r *Report) errorf(format string, args ...interface{}) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+func (r *Report) warnf(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// ValidateSitesList checks every record of the sites-list file (see wordpress.ReadSitesList), printing a report per row (to STDOUT):
+// the UUID format of OwnerID and ChnID, duplicate handles, slugs and hosts,
+// reachability of HostURL, availability of the API of its source (see source.Source.Describe),
+// and presence of its credentials. Returns error if any record has errors.
+func ValidateSitesList(env *client.Env) error {
+	sites, err := wordpress.ReadSitesList(env)
+	if err != nil {
+		return errors.Wrap(err, "reading sites list")
+	}
+	reports := ValidateSites(env, sites)
+
+	fails, warns := 0, 0
+	if env.SitesPass == "" {
+		fmt.Printf("sites list : ERR : missing sites password (SitesPass)\n")
+		fails++
+	}
+	for _, r := range reports {
+		if len(r.Errors) == 0 && len(r.Warnings) == 0 {
+			fmt.Printf("row %3d : %-24s : OK\n", r.Row, r.Handle)
+			continue
+		}
+		for _, e := range r.Errors {
+			fmt.Printf("row %3d : %-24s : ERR : %s\n", r.Row, r.Handle, e)
+		}
+		for _, w := range r.Warnings {
+			fmt.Printf("row %3d : %-24s : WARN : %s\n", r.Row, r.Handle, w)
+		}
+		if len(r.Errors) > 0 {
+			fails++
+		}
+		if len(r.Warnings) > 0 {
+			warns++
+		}
+	}
+	fmt.Printf("\nsites list : rows: %d : errors: %d : warnings: %d\n", len(reports), fails, warns)
+	if fails > 0 {
+		return errors.Errorf("sites list INVALID : %d of errors", fails)
+	}
+	return nil
+}
+
+// ValidateSites returns the Report of each site (record) of a sites list; see ValidateSitesList.
+// Sites of a malformed record are checked no further; others reachable are described thereby, by reference.
+func ValidateSites(env *client.Env, sites []mirror.Site) []Report {
+	reports := make([]Report, len(sites))
+
+	// Duplicates, per field, of rows by value
+	var (
+		handles = map[string][]int{}
+		slugs   = map[string][]int{}
+		hosts   = map[string][]int{}
+		chnIDs  = map[string][]int{}
+	)
+	for i, site := range sites {
+		reports[i] = Report{Row: site.Row, Handle: site.UserHandle}
+		if site.Error != "" {
+			continue
+		}
+		handles[strings.ToLower(site.UserHandle)] = append(handles[strings.ToLower(site.UserHandle)], i)
+		slugs[strings.ToLower(site.ChnSlug)] = append(slugs[strings.ToLower(site.ChnSlug)], i)
+		if h := mirror.Host(site.HostURL); h != "" {
+			hosts[h] = append(hosts[h], i)
+		}
+		chnIDs[strings.ToLower(site.ChnID)] = append(chnIDs[strings.ToLower(site.ChnID)], i)
+	}
+	dups := func(field string, idx map[string][]int) {
+		for val, ii := range idx {
+			if val == "" || len(ii) < 2 {
+				continue
+			}
+			for _, i := range ii {
+				rows := []string{}
+				for _, j := range ii {
+					if j != i {
+						rows = append(rows, fmt.Sprint(sites[j].Row))
+					}
+				}
+				reports[i].errorf("duplicate %s (%s) of row %s", field, val, strings.Join(rows, ", "))
+			}
+		}
+	}
+	dups("handle", handles)
+	dups("slug", slugs)
+	dups("host", hosts)
+	dups("chn_id", chnIDs)
+
+	for i := range sites {
+		site := &sites[i]
+		r := &reports[i]
+		if site.Error != "" {
+			r.errorf("%s", site.Error)
+			continue
+		}
+		env.Logger.Printf("INFO : validate @ row %d : %s\n", site.Row, site.UserHandle)
+		validateSite(env, site, r)
+	}
+	return reports
+}
+
+// validateSite checks the fields of a site, and its source per requests thereto, into its report (r);
+// describing the site thereby (see source.Source.Describe) if reachable.
+func validateSite(env *client.Env, site *mirror.Site, r *Report) {
+	if site.UserHandle == "" {
+		r.errorf("missing handle")
+	}
+	if site.ChnSlug == "" {
+		r.errorf("missing slug")
+	}
+	if _, err := uuid.FromString(site.OwnerID); err != nil {
+		r.errorf("owner_id : malformed UUID : %q", site.OwnerID)
+	}
+	if _, err := uuid.FromString(site.ChnID); err != nil {
+		r.errorf("chn_id : malformed UUID : %q", site.ChnID)
+	}
+
+	// Local and fediverse sources may declare no host.
+	local := site.Source == source.Markdown || site.Source == source.ActivityPub
+	reachable := true
+	switch u, err := neturl.Parse(site.HostURL); {
+	case site.HostURL == "" && local:
+	case site.HostURL == "":
+		r.errorf("missing host_url")
+		reachable = false
+	case err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https"):
+		r.errorf("host_url : malformed URL : %q", site.HostURL)
+		reachable = false
+	case u.Scheme == "http":
+		r.warnf("host_url : not https : %s", site.HostURL)
+	}
+
+	if !site.Enabled() {
+		r.warnf("disabled : neither reachability nor source checked")
+		reachable = false
+	}
+
+	// Reachability
+	switch {
+	case !reachable:
+	case site.Source == source.Markdown:
+		if fi, err := os.Stat(site.Dir); err != nil || !fi.IsDir() {
+			r.errorf("dir NOT FOUND : %q", site.Dir)
+			reachable = false
+		}
+	case site.HostURL != "":
+		rsp := env.Get(site.HostURL, client.HTML)
+		site.Pause()
+		if rsp.Error != "" {
+			r.errorf("host_url UNREACHABLE : HTTP %d : %s", rsp.Code, rsp.Error)
+			reachable = false
+		}
+	}
+
+	// Credentials
+	switch {
+	case site.Auth == "" && site.Source == source.Ghost:
+		r.errorf("auth : missing reference to Content API key")
+	case site.Auth == "":
+	case site.Source == "" || site.Source == source.WordPress:
+		if _, _, err := mirror.Credentials(env.NS, site.Auth); err != nil {
+			r.errorf("auth : %s", err.Error())
+		}
+	default:
+		if _, err := mirror.Secret(env.NS, site.Auth); err != nil {
+			r.errorf("auth : %s", err.Error())
+		}
+	}
+
+	// Availability of the API (REST, feed, ...) of its source
+	if !reachable {
+		return
+	}
+	src, err := source.New(env, site)
+	if err != nil {
+		r.errorf("%s", err.Error())
+		return
+	}
+	src.Describe()
+	switch {
+	case site.Error != "":
+		r.errorf("source (%s) UNAVAILABLE : %s : HTTP %d @ %s", sourceName(site), site.Error, site.Status.Code, site.Status.Object)
+	case site.Name == "":
+		r.warnf("source (%s) describes no name", sourceName(site))
+	}
+}
+
+// sourceName returns that of the source of a site.
+func sourceName(site *mirror.Site) string {
+	if site.Source == "" {
+		return source.WordPress
+	}
+	return site.Source
+}
@@ -4,7 +4,6 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"time"
 
@@ -16,6 +15,7 @@ import (
 	"github.com/sempernow/kit/timestamp"
 	"github.com/sempernow/kit/types/convert"
 	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
 	"github.com/sempernow/uqc/client/wordpress"
 
 	"github.com/pkg/errors"
@@ -24,7 +24,7 @@ import (
 const DESCRIBE = `
 	env         :     PrettyPrint the environment (Env) struct.
 	get         :     Dump response body of GET to STDOUT and HTTP status to STDERR.
-	                  	get $url ['html'|'json'(default)]
+	                  	get $url ['html'|'xml'|'json'(default)]
 	posttkn     :     Dump response body of token-authenticated POST 
 	                  	to STDOUT and HTTP status to STDERR.
 	postkey     :     Dump response body of key-authenticated POST 
@@ -37,7 +37,20 @@ const DESCRIBE = `
 	key         :     Get key from token and store in cache.
 	                  	key [$cid] |jq -Mr .body
 
-	siteslist   :     Make a new sites list from CSV sources list (env.SitesListCSV).
+	siteslist   :     Make a new sites list from sources list (env.SitesListCSV);
+	                  	CSV (positional or header-named fields), else JSON or YAML (version: 1).
+	                  	siteslist validate : Report per row of sources list (to STDOUT);
+	                  	exit 1 on any errors.
+	                  	siteslist --from-service : Make per hosted channels of Uqrate API (sans DB access),
+	                  	merged (by chn_id) with options of sources list, if any.
+	                  	siteslist diff [--from-service] : Diff (to STDOUT) of current sites list against one made anew,
+	                  	sans caching; sites of Status.Code or Error flipped are marked "!!".
+	                  	Else printed following each (re)make.
+
+	site        :     Edit the sites list in place; both its file and JSON (Docker config and cache),
+	                  	getting the dynamic fields of only the site affected.
+	                  	site add user_handle=$h slug=$s host_url=$url owner_id=$uid chn_id=$cid [option=value ...]
+	                  	site rm|enable|disable|show $handle
 
 	updateusers :     Update all users of sites list.
 
@@ -48,8 +61,18 @@ const DESCRIBE = `
 	upsertpostschron : Repeatedly run upsertposts command every x hours 
 	                   	upsertpostschron $hours
 	
+	mirrorfeed  :     Upsert all items of a JSON Feed into the channel of a site of sites list.
+	                  	mirrorfeed $url $handle
+
+	publishmd   :     Upsert the Markdown files (of changed content) of a local directory
+	                  	into the channel of a site of sites list.
+	                  	publishmd $dir $handle
+
+	migrateids  :     Record aliases of messages mirrored under the legacy (URI) identity
+	                  	of sites declaring another (identity=id|guid), so those are not mirrored anew.
+
 	purgecachetkns
-	purgecacheposts (*_posts.json, *_msgs.json)
+	purgecacheposts (*_<type>.json, *_comments.post.*.json, *_msgs.json) ; <type> is REST base (posts, pages, ...)
 
 	uptkn       :     Upsert a long-form message of hosted channel using JWT authentication.
 	                  	uptkn $json [$jwt [$slug]]
@@ -130,11 +153,34 @@ func run() error {
 		commands.UpsertPostsChron(env, convert.ToInt(env.Args.Num(1)))
 
 	case "siteslist":
-		fmt.Printf("\n=== Make & cache new sites list (JSON)\n")
-		sites := wordpress.MakeSitesList(env)
+		sites := []mirror.Site{}
+		switch env.Args.Num(1) {
+		case "validate":
+			return commands.ValidateSitesList(env)
+		case "diff":
+			return commands.DiffSitesList(env, env.Args.Num(2) == "--from-service")
+		}
+		old, err := wordpress.ReadSitesListJSON(env)
+		if err != nil {
+			env.Logger.Printf("WARN : current sites list : %s\n", err.Error())
+		}
+		switch env.Args.Num(1) {
+		case "--from-service":
+			fmt.Printf("\n=== Make & cache new sites list (JSON) from service\n")
+			ss, err := wordpress.MakeSitesListFromService(env, commands.DescribeSite)
+			if err != nil {
+				return err
+			}
+			sites = ss
+		default:
+			fmt.Printf("\n=== Make & cache new sites list (JSON)\n")
+			sites = wordpress.MakeSitesList(env, commands.DescribeSite)
+		}
 		if err := env.SetCache(env.SitesListJSON, convert.Stringify(sites)); err != nil {
 			return err
 		}
+		fmt.Printf("\n=== Diff of sites list (JSON) against that replaced\n")
+		commands.PrintSitesDiff(old, sites)
 
 	case "updateusers":
 		commands.UpdateUsers(env)
@@ -142,28 +188,37 @@ func run() error {
 		commands.UpsertChannels(env)
 	case "upsertposts":
 		commands.UpsertPosts(env)
+	case "migrateids":
+		commands.MigrateIDs(env)
+	case "mirrorfeed":
+		if err := commands.MirrorFeed(env, env.Args.Num(1), env.Args.Num(2)); err != nil {
+			return err
+		}
+	case "publishmd":
+		if err := commands.PublishMarkdown(env, env.Args.Num(1), env.Args.Num(2)); err != nil {
+			return err
+		}
 	case "purgecachetkns":
 		commands.PurgeCacheTkns(env)
 	case "purgecacheposts":
 		commands.PurgeCachePosts(env)
 
 	case "site":
-		site := wordpress.Site{
-			//URL: "https://ComicsGate.org",
-			//URL: "https://TheDuran.com",
-			HostURL: "https://TheCritic.co.uk",
-			ChnID:   "d5750f33-a12d-4719-9600-94fcee80f487",
-		}
-		wp := wordpress.NewWordPress(env, &site)
-		wp.SitePosts()
-		// fmt.Println(convert.PrettyPrint(site))
-		msgs := wp.PostsToMsgs()
-		if len(msgs) == 0 {
-			fmt.Fprintf(os.Stderr, "WARN : NO MESSAGES @ %s", err)
+		handle := env.Args.Num(2)
+		switch env.Args.Num(1) {
+		case "add":
+			return commands.SiteAdd(env, env.Args[2:]...)
+		case "rm":
+			return commands.SiteRemove(env, handle)
+		case "enable":
+			return commands.SiteEnable(env, handle, true)
+		case "disable":
+			return commands.SiteEnable(env, handle, false)
+		case "show":
+			return commands.SiteShow(env, handle)
+		default:
+			return errors.Errorf("unknown site command : %q (add|rm|enable|disable|show)", env.Args.Num(1))
 		}
-		//fmt.Printf("%s", convert.Stringify(mm))
-		path := env.Cache + "/" + "TheCritic.co.uk_msgs.json"
-		ioutil.WriteFile(path, []byte(convert.Stringify(msgs)), 0664)
 
 	case "trace":
 		endpt := env.Args.Num(1)
@@ -270,7 +325,7 @@ func run() error {
 	// fmt.Printf("%s\n", convert.Stringify(posts))
 
 	case "wpuptkn":
-		site := wordpress.Site{
+		site := mirror.Site{
 			//URL: "https://ComicsGate.org",
 			//URL: "https://TheDuran.com",
 			HostURL: "https://TheCritic.co.uk",
@@ -292,7 +347,7 @@ func run() error {
 		}
 	case "wpupkey":
 		key := env.Args.Num(1)
-		site := wordpress.Site{
+		site := mirror.Site{
 			//URL: "https://ComicsGate.org",
 			//URL: "https://TheDuran.com",
 			HostURL: "https://TheCritic.co.uk",
@@ -72,6 +72,7 @@ func NewEnv(osArgs []string) (*client.Env, error) {
 
 			UserAgent  string        `conf:"default:uqc/dev"`
 			Timeout    time.Duration `conf:"default:5s"`
+			Workers    int           `conf:"default:8"`
 			TraceLevel int           `conf:"default:1"`
 			TraceDump  bool          `conf:"default:false"`
 			TraceFpath string        `conf:"default:./client.trace-resp.dump"`
@@ -145,6 +146,7 @@ func NewEnv(osArgs []string) (*client.Env, error) {
 
 			UserAgent:  cfg.Client.UserAgent,
 			Timeout:    cfg.Client.Timeout,
+			Workers:    cfg.Client.Workers,
 			TraceLevel: cfg.Client.TraceLevel,
 			TraceDump:  cfg.Client.TraceDump,
 			TraceFpath: cfg.Client.TraceFpath,
@@ -0,0 +1,461 @@
+// Package activitypub is the source adapter of a fediverse (Mastodon, ...) account, per the public outbox of its actor.
+package activitypub
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	neturl "net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
+)
+
+// MaxPages limits the pages of the outbox fetched per run.
+const MaxPages = 10
+
+// TitleMax is the (rune) length of the title derived from the content of an untitled object (Note).
+const TitleMax = 80
+
+// Reasons an activity of the outbox is not mirrored.
+const (
+	SkipBoost   = "boost"
+	SkipReply   = "reply"
+	SkipPrivate = "not public"
+)
+
+// Title and body of the message upserted in place of that whose object was deleted,
+// the Uqrate service having no endpoint of deletion.
+const (
+	RemovedTitle = "[removed]"
+	RemovedBody  = "<p><em>This post was removed by its author.</em></p>"
+)
+
+// Item is an object of the outbox per its latest activity; Removed if that is a Delete.
+type Item struct {
+	Object
+	Date    time.Time `json:"date"` // Of the activity
+	Removed bool      `json:"removed,omitempty"`
+}
+
+// SuffixMirrored is that of the cache key of the ids of messages mirrored per site.
+const SuffixMirrored = "_ap_mirrored.json"
+
+// Mirrored is the set of ids of messages upserted of objects not (yet) deleted.
+type Mirrored map[string]bool
+
+// ActivityPub is the source of a site per the outbox of its actor (Site.Actor).
+// Creates and Updates of public Notes, Articles, Pages and Questions are mirrored per their latest state.
+// Boosts (Announce), replies and non-public objects are recorded at Site.Skipped.
+// Each object maps to a message of its IRI (URI), title (its name, else content warning, else leading text),
+// content and attachments (folded under its content warning, if any), hashtags and actor (tags),
+// and its updated, else published, else activity time. Its IRI is its identity, regardless of Site.Identity.
+// A Delete maps to RemovedTitle and RemovedBody, yet only of an object mirrored prior (see Commit).
+type ActivityPub struct {
+	mirror.Adapter
+	actor    *Actor
+	Items    []Item
+	mirrored Mirrored
+}
+
+// New returns the ActivityPub source of a site.
+func New(env *client.Env, site *mirror.Site) *ActivityPub {
+	return &ActivityPub{Adapter: mirror.NewAdapter(env, site)}
+}
+
+// Describe merges the profile of the actor (name, summary, url, icon) into its Site record.
+func (ap *ActivityPub) Describe() {
+	a, err := ap.Actor()
+	if err != nil {
+		ap.Site.Error = err.Error()
+		return
+	}
+	site := ap.Site
+	site.Name = a.Name
+	if site.Name == "" {
+		site.Name = a.PreferredUsername
+	}
+	site.Description = mirror.HTMLToText(a.Summary)
+	if u := href(a.URL); u != "" {
+		site.URL = u
+		site.Home = u
+	}
+	if icon := href(a.Icon); icon != "" {
+		site.Icon = icon
+	}
+}
+
+// Fetch retrieves the objects of the outbox of the actor per activities since the checkpoint (since); all if zero.
+// The outbox being reverse chronological, pages are followed until an activity older than since.
+func (ap *ActivityPub) Fetch(since time.Time) {
+	site := ap.Site
+	a, err := ap.Actor()
+	if err != nil {
+		site.Error = err.Error()
+		return
+	}
+	if a.Outbox == "" {
+		site.Error = "actor has no outbox"
+		return
+	}
+	page := &Collection{}
+	if err := ap.get(a.Outbox, page); err != nil {
+		site.Error = err.Error()
+		return
+	}
+	if len(page.OrderedItems) == 0 && len(page.Items) == 0 {
+		if page, err = ap.page(page.First); err != nil {
+			site.Error = err.Error()
+			return
+		}
+	}
+	seen := map[string]bool{}
+	for i := 0; i < MaxPages; i++ {
+		for _, act := range append(page.OrderedItems, page.Items...) {
+			t := mirror.ParseTime(act.Published)
+			if !since.IsZero() && !t.IsZero() && !t.After(since) {
+				return
+			}
+			ap.take(&act, t, seen)
+		}
+		if href(page.Next) == "" {
+			return
+		}
+		if page, err = ap.page(page.Next); err != nil {
+			site.Error = err.Error()
+			return
+		}
+	}
+}
+
+// take records the object of an activity (act) of time (t), unless that of a newer activity was (seen).
+func (ap *ActivityPub) take(act *Activity, t time.Time, seen map[string]bool) {
+	site := ap.Site
+	switch act.Type {
+	case TypeCreate, TypeUpdate, TypeDelete:
+	case TypeAnnounce:
+		site.Skipped = append(site.Skipped, mirror.Skip{Link: act.ID, Reason: SkipBoost})
+		return
+	default:
+		return
+	}
+	obj := Object{}
+	if err := json.Unmarshal(act.Object, &obj); err != nil {
+		obj.ID = href(act.Object) // Referenced, not embedded.
+	}
+	if obj.ID == "" || seen[obj.ID] {
+		return
+	}
+	seen[obj.ID] = true
+
+	if act.Type == TypeDelete || obj.Type == TypeTombstone {
+		ap.Items = append(ap.Items, Item{Object: obj, Date: t, Removed: true})
+		return
+	}
+	if obj.Type == "" {
+		if err := ap.get(obj.ID, &obj); err != nil {
+			log.Printf("WARN : object @ %s : %s\n", obj.ID, err.Error())
+			return
+		}
+	}
+	switch obj.Type {
+	case TypeNote, TypeArticle, TypePage, TypeQuestion:
+	default:
+		return
+	}
+	reason := ""
+	switch {
+	case !public(obj.To, obj.Cc, act.To, act.Cc):
+		reason = SkipPrivate
+	case href(obj.InReplyTo) != "":
+		reason = SkipReply
+	}
+	if reason != "" {
+		log.Printf("INFO : SKIP object %s : %s\n", obj.ID, reason)
+		site.Skipped = append(site.Skipped, mirror.Skip{Link: obj.ID, Reason: reason})
+		return
+	}
+	ap.Items = append(ap.Items, Item{Object: obj, Date: t})
+}
+
+// Messages maps the fetched objects into Uqrate messages.
+func (ap *ActivityPub) Messages() []client.Message {
+	msgs := []client.Message{}
+	for _, item := range ap.Items {
+		msg := ap.ItemToMsg(&item)
+		if msg.ID == "" {
+			continue
+		}
+		if item.Removed && !ap.isMirrored(msg.ID) {
+			log.Printf("INFO : SKIP removal of object %s : not mirrored\n", item.ID)
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// Commit records the ids of messages upserted per object, so that only those are removed per a later Delete;
+// dropping those of removals upserted.
+func (ap *ActivityPub) Commit(upserted []client.Message) {
+	if ap.mirrored == nil {
+		ap.mirrored = ap.loadMirrored()
+	}
+	ids := map[string]bool{}
+	for _, msg := range upserted {
+		ids[msg.ID] = true
+	}
+	n := 0
+	for _, item := range ap.Items {
+		id := ap.itemID(&item)
+		if id == "" || !ids[id] {
+			continue
+		}
+		if item.Removed {
+			delete(ap.mirrored, id)
+		} else {
+			ap.mirrored[id] = true
+		}
+		n++
+	}
+	if n == 0 {
+		return
+	}
+	bb, err := json.Marshal(ap.mirrored)
+	if err != nil {
+		log.Printf("ERR : Marshalling : %s\n", err.Error())
+		return
+	}
+	if err := ap.Env.SetCache(ap.mirroredKey(), string(bb)); err != nil {
+		log.Printf("ERR : SetCache @ %s : %s\n", ap.mirroredKey(), err.Error())
+	}
+}
+
+// isMirrored reports whether the message (id) was upserted of an object since not removed.
+func (ap *ActivityPub) isMirrored(id string) bool {
+	if ap.mirrored == nil {
+		ap.mirrored = ap.loadMirrored()
+	}
+	return ap.mirrored[id]
+}
+
+// mirroredKey returns the cache key of the ids of messages mirrored of the site.
+func (ap *ActivityPub) mirroredKey() string {
+	return ap.Site.UserHandle + SuffixMirrored
+}
+
+// loadMirrored reads the ids of messages mirrored of the site from cache.
+func (ap *ActivityPub) loadMirrored() Mirrored {
+	mm := Mirrored{}
+	bb := ap.Env.GetCache(ap.mirroredKey())
+	if len(bb) == 0 {
+		return mm
+	}
+	if err := json.Unmarshal(bb, &mm); err != nil {
+		log.Printf("ERR : Unmarshalling : %s\n", err.Error())
+	}
+	return mm
+}
+
+// itemID returns the message id of an item per its IRI; empty if that is not of the site.
+func (ap *ActivityPub) itemID(item *Item) string {
+	uri, err := mirror.LinkToURI(item.ID, ap.Site.HostURL)
+	if err != nil {
+		return ""
+	}
+	return mirror.MessageID(ap.Site.ChnID, uri)
+}
+
+// ItemToMsg denormalizes an object of the outbox into a Uqrate message;
+// its id per that (IRI) of the object, being all declared of one deleted.
+func (ap *ActivityPub) ItemToMsg(item *Item) client.Message {
+	msg := client.Message{}
+	site := ap.Site
+
+	msg.ChnID = site.ChnID
+	var err error
+	if msg.URI, err = mirror.LinkToURI(item.ID, site.HostURL); err != nil {
+		log.Printf("ERR : LinkToURI : object %s : %s\n", item.ID, err.Error())
+		return client.Message{}
+	}
+	msg.ID = mirror.MessageID(msg.ChnID, msg.URI)
+	if msg.ID == "" {
+		log.Printf("ERR : UUIDv5 fail : URI: %s .\n", msg.URI)
+		return client.Message{URI: msg.URI}
+	}
+
+	if item.Removed {
+		msg.Title = RemovedTitle
+		msg.Body = RemovedBody
+		msg.DateUpdate = mirror.DateUpdate(mirror.ParseTime(item.Deleted), item.Date)
+		return msg
+	}
+
+	// The summary of a Note is its content warning (plain text); that of an Article its summary.
+	cw := ""
+	if item.Type != TypeArticle && item.Type != TypePage {
+		cw = strings.TrimSpace(item.Summary)
+	}
+	msg.Title = item.Name
+	if msg.Title == "" {
+		msg.Title = cw
+	}
+	if msg.Title == "" {
+		msg.Title = mirror.Summarize(mirror.HTMLToText(item.Content), 1, TitleMax)
+	}
+	if msg.Title == "" {
+		msg.Title = "Untitled"
+	}
+
+	msg.Body = item.Content + media(item.Attachment)
+	if cw != "" {
+		msg.Body = "<details><summary>" + html.EscapeString(cw) + "</summary>" + msg.Body + "</details>"
+	}
+	if ap.Cleaner != nil {
+		msg.Body = ap.Cleaner(msg.Body)
+	}
+
+	msg.Summary = ap.Summary(html.EscapeString(item.Summary), item.Content)
+
+	for _, tag := range item.Tag {
+		if tag.Type == TypeHashtag {
+			msg.Tags = append(msg.Tags, strings.TrimPrefix(tag.Name, "#"))
+		}
+	}
+	if ap.actor != nil {
+		msg.Tags = mirror.AuthorTag(msg.Tags, ap.actor.Name)
+	}
+
+	mirror.Sanitize(msg.Tags)
+
+	msg.DateUpdate = mirror.DateUpdate(
+		mirror.ParseTime(item.Updated),
+		mirror.ParseTime(item.Published),
+		item.Date,
+	)
+
+	return msg
+}
+
+// Actor returns the actor of the site (Site.Actor), declared by its URL, else its account (@user@host);
+// fetched once per ActivityPub.
+func (ap *ActivityPub) Actor() (*Actor, error) {
+	if ap.actor != nil {
+		return ap.actor, nil
+	}
+	id := strings.TrimSpace(ap.Site.Actor)
+	if id == "" {
+		return nil, errors.New("missing actor")
+	}
+	if !strings.HasPrefix(id, "http") {
+		url, err := ap.webfinger(id)
+		if err != nil {
+			return nil, err
+		}
+		id = url
+	}
+	a := Actor{}
+	if err := ap.get(id, &a); err != nil {
+		return nil, err
+	}
+	ap.actor = &a
+	return ap.actor, nil
+}
+
+// webfinger resolves an account (@user@host) into the URL of its actor.
+func (ap *ActivityPub) webfinger(acct string) (string, error) {
+	acct = strings.TrimPrefix(strings.TrimPrefix(acct, "acct:"), "@")
+	ss := strings.SplitN(acct, "@", 2)
+	if len(ss) != 2 || ss[0] == "" || ss[1] == "" {
+		return "", errors.Errorf("malformed account : %s", acct)
+	}
+	url := "https://" + ss[1] + "/.well-known/webfinger?resource=" + neturl.QueryEscape("acct:"+acct)
+	jrd := Webfinger{}
+	if err := ap.getAs(url, client.JSON, &jrd); err != nil {
+		return "", err
+	}
+	for _, l := range jrd.Links {
+		if l.Rel == "self" && l.Href != "" && (strings.Contains(l.Type, "activity+json") || strings.Contains(l.Type, "ld+json")) {
+			return l.Href, nil
+		}
+	}
+	return "", errors.Errorf("no actor of account : %s", acct)
+}
+
+// page returns a page of the outbox per its reference (raw); embedded, else fetched.
+func (ap *ActivityPub) page(raw json.RawMessage) (*Collection, error) {
+	c := Collection{}
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{")) {
+		if err := json.Unmarshal(raw, &c); err == nil && (len(c.OrderedItems) > 0 || len(c.Items) > 0) {
+			return &c, nil
+		}
+	}
+	url := href(raw)
+	if url == "" {
+		return nil, errors.New("outbox has no page")
+	}
+	if err := ap.get(url, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// get fetches and decodes an Activity Streams document (url) into ptr.
+func (ap *ActivityPub) get(url string, ptr interface{}) error {
+	return ap.getAs(url, client.ACTIVITY, ptr)
+}
+
+// getAs fetches and decodes a JSON document (url) of content type (cType) into ptr.
+func (ap *ActivityPub) getAs(url, cType string, ptr interface{}) error {
+	rsp := ap.Env.Get(url, cType)
+	ap.Site.Pause()
+
+	ap.Site.Status.Object = url
+	ap.Site.Status.Code = rsp.Code
+
+	if rsp.Error != "" {
+		return errors.New(rsp.Error)
+	}
+	if rsp.Body == "" {
+		return errors.New("GET returned nothing")
+	}
+	if err := json.Unmarshal([]byte(rsp.Body), ptr); err != nil {
+		log.Printf("ERR : Unmarshalling : %s\n", err.Error())
+		return err
+	}
+	return nil
+}
+
+// media renders the attachments of an object; images as figures, video and audio as players, else links.
+func media(aa []Attachment) string {
+	var b strings.Builder
+	for _, a := range aa {
+		src := href(a.URL)
+		if src == "" {
+			continue
+		}
+		kind := strings.ToLower(a.Type)
+		if i := strings.Index(a.MediaType, "/"); i > 0 {
+			kind = a.MediaType[:i]
+		}
+		switch kind {
+		case "image":
+			b.WriteString(mirror.Figure(src, a.Name, a.Width, a.Height))
+		case "video", "audio":
+			fmt.Fprintf(&b, `<figure><%s controls preload="none" src="%s"></%s></figure>`, kind, html.EscapeString(src), kind)
+		default:
+			name := a.Name
+			if name == "" {
+				name = path.Base(src)
+			}
+			fmt.Fprintf(&b, `<p><a href="%s">%s</a></p>`, html.EscapeString(src), html.EscapeString(name))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,195 @@
+package activitypub
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
+)
+
+const testChnID = "e2b3e5b4-1b0e-4c8e-8f1b-3c1b7e0e5a11"
+
+const testPublic = `"to": ["https://www.w3.org/ns/activitystreams#Public"]`
+
+// Pages of the outbox, newest first; the first links the next. Statuses (%[1]s/s/<n>) are those of the actor.
+var testOutbox = []string{`{
+	"type": "OrderedCollectionPage",
+	"next": "%[1]s/outbox?page=2",
+	"orderedItems": [
+		{"type": "Update", "published": "2024-01-05T00:00:00Z", ` + testPublic + `,
+			"object": {"id": "%[1]s/s/1", "type": "Note", "content": "<p>Edited. More text.</p>", "published": "2024-01-02T00:00:00Z", "updated": "2024-01-05T00:00:00Z", ` + testPublic + `,
+				"tag": [{"type": "Hashtag", "name": "#golang"}, {"type": "Mention", "name": "@bob"}]}},
+		{"type": "Delete", "published": "2024-01-04T00:00:00Z", ` + testPublic + `,
+			"object": {"id": "%[1]s/s/2", "type": "Tombstone", "deleted": "2024-01-04T00:00:00Z"}},
+		{"id": "%[1]s/boost", "type": "Announce", "published": "2024-01-03T00:00:00Z", ` + testPublic + `, "object": "https://else.where/s/9"},
+		{"type": "Create", "published": "2024-01-02T00:00:00Z", ` + testPublic + `,
+			"object": {"id": "%[1]s/s/1", "type": "Note", "content": "<p>Original.</p>", ` + testPublic + `}}
+	]
+}`, `{
+	"type": "OrderedCollectionPage",
+	"orderedItems": [
+		{"type": "Delete", "published": "2024-01-01T12:00:00Z", ` + testPublic + `, "object": "%[1]s/s/3"},
+		{"type": "Create", "published": "2024-01-01T06:00:00Z", ` + testPublic + `,
+			"object": {"id": "%[1]s/s/2", "type": "Note", "content": "<p>Since deleted.</p>", ` + testPublic + `}},
+		{"type": "Create", "published": "2024-01-01T00:00:00Z", ` + testPublic + `,
+			"object": {"id": "%[1]s/s/4", "type": "Note", "content": "<p>A reply.</p>", "inReplyTo": "https://else.where/s/8", ` + testPublic + `}},
+		{"type": "Create", "published": "2023-12-31T12:00:00Z", "to": ["%[1]s/followers"],
+			"object": {"id": "%[1]s/s/5", "type": "Note", "content": "<p>Followers only.</p>", "to": ["%[1]s/followers"]}},
+		{"type": "Create", "published": "2023-12-31T00:00:00Z", ` + testPublic + `,
+			"object": {"id": "%[1]s/s/6", "type": "Article", "name": "The Article", "summary": "Of it.", "content": "<p>Body.</p>", "published": "2023-12-31T00:00:00Z", ` + testPublic + `}}
+	]
+}`}
+
+// testActivityPub returns an ActivityPub of an actor at an httptest server, of env (cache) shared across runs,
+// and the number of outbox pages fetched thereof.
+func testActivityPub(t *testing.T, env *client.Env) (*ActivityPub, *int64) {
+	t.Helper()
+	var (
+		srv   *httptest.Server
+		pages int64
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/actor", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id": "%[1]s/actor", "type": "Person", "name": "Ann", "outbox": "%[1]s/outbox"}`, srv.URL)
+	})
+	mux.HandleFunc("/outbox", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "":
+			fmt.Fprintf(w, `{"type": "OrderedCollection", "totalItems": 9, "first": "%s/outbox?page=1"}`, srv.URL)
+		case "1":
+			atomic.AddInt64(&pages, 1)
+			fmt.Fprintf(w, testOutbox[0], srv.URL)
+		case "2":
+			atomic.AddInt64(&pages, 1)
+			fmt.Fprintf(w, testOutbox[1], srv.URL)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	site := &mirror.Site{
+		UserHandle: "test", HostURL: srv.URL, ChnID: testChnID, Source: "activitypub", Actor: srv.URL + "/actor",
+		RateLimit: mirror.Duration(time.Millisecond),
+	}
+	return New(env, site), &pages
+}
+
+func testEnv(t *testing.T) *client.Env {
+	return &client.Env{
+		Logger: log.New(io.Discard, "", 0),
+		Cache:  t.TempDir(),
+		Client: client.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func TestFetch(t *testing.T) {
+	tests := []struct {
+		name    string
+		since   time.Time
+		pages   int64
+		items   []string // Of status (n); removed (-n)
+		skipped []string
+	}{
+		{"all", time.Time{}, 2, []string{"1", "-2", "-3", "6"}, []string{SkipBoost, SkipReply, SkipPrivate}},
+		{"since", time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), 1, []string{"1", "-2"}, []string{}},
+		{"since older", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 2, []string{"1", "-2", "-3"}, []string{SkipBoost}},
+	}
+	for _, tt := range tests {
+		ap, pages := testActivityPub(t, testEnv(t))
+		ap.Fetch(tt.since)
+		if ap.Site.Error != "" {
+			t.Errorf("%s : %s", tt.name, ap.Site.Error)
+			continue
+		}
+		if *pages != tt.pages {
+			t.Errorf("%s : pages fetched %d, want %d", tt.name, *pages, tt.pages)
+		}
+		items := []string{}
+		for _, item := range ap.Items {
+			n := item.ID[strings.LastIndex(item.ID, "/")+1:]
+			if item.Removed {
+				n = "-" + n
+			}
+			items = append(items, n)
+		}
+		if fmt.Sprint(items) != fmt.Sprint(tt.items) {
+			t.Errorf("%s : items %v, want %v", tt.name, items, tt.items)
+		}
+		skipped := []string{}
+		for _, s := range ap.Site.Skipped {
+			skipped = append(skipped, s.Reason)
+		}
+		if fmt.Sprint(skipped) != fmt.Sprint(tt.skipped) {
+			t.Errorf("%s : skipped %v, want %v", tt.name, skipped, tt.skipped)
+		}
+	}
+}
+
+// A removal (Delete, Tombstone) is a message only of an object mirrored prior; see Commit.
+func TestMessagesRemoved(t *testing.T) {
+	env := testEnv(t)
+	run := func() (*ActivityPub, map[string]client.Message) {
+		ap, _ := testActivityPub(t, env)
+		ap.Fetch(time.Time{})
+		msgs := map[string]client.Message{}
+		for _, msg := range ap.Messages() {
+			msgs[msg.URI] = msg
+		}
+		return ap, msgs
+	}
+
+	ap, msgs := run()
+	if len(msgs) != 2 {
+		t.Fatalf("run 1 : got %d messages, want 2 (removals of objects not mirrored dropped)", len(msgs))
+	}
+	note, article := msgs["/s/1"], msgs["/s/6"]
+	if note.Title != "Edited." || note.Body != "<p>Edited. More text.</p>" {
+		t.Errorf("note : title %q, body %q, want that of its latest activity", note.Title, note.Body)
+	}
+	if fmt.Sprint(note.Tags) != "[golang Ann]" || !note.DateUpdate.Equal(time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("note : tags %v, date %v", note.Tags, note.DateUpdate)
+	}
+	if article.Title != "The Article" || article.Summary != "Of it." || strings.Contains(article.Body, "<details>") {
+		t.Errorf("article : title %q, summary %q, body %q", article.Title, article.Summary, article.Body)
+	}
+
+	// Of status 2 mirrored prior (e.g., before its Delete), the removal is a message; committed, no longer.
+	id2 := mirror.MessageID(testChnID, "/s/2")
+	if err := env.SetCache(ap.mirroredKey(), fmt.Sprintf(`{%q: true}`, id2)); err != nil {
+		t.Fatal(err)
+	}
+	ap, msgs = run()
+	removed, ok := msgs["/s/2"]
+	if !ok || removed.ID != id2 || removed.Title != RemovedTitle || removed.Body != RemovedBody {
+		t.Fatalf("run 2 : removal of status 2 : got %+v", removed)
+	}
+	if !removed.DateUpdate.Equal(time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("run 2 : removal date %v, want that deleted", removed.DateUpdate)
+	}
+	if _, ok := msgs["/s/3"]; ok {
+		t.Errorf("run 2 : removal of status 3, never mirrored")
+	}
+	upserted := []client.Message{}
+	for _, msg := range msgs {
+		upserted = append(upserted, msg)
+	}
+	ap.Commit(upserted)
+
+	_, msgs = run()
+	if _, ok := msgs["/s/2"]; ok || len(msgs) != 2 {
+		t.Errorf("run 3 : got %d messages, want 2 (removal committed)", len(msgs))
+	}
+	if mm := ap.loadMirrored(); mm[id2] || !mm[note.ID] || !mm[article.ID] {
+		t.Errorf("mirrored : got %v", mm)
+	}
+}
@@ -0,0 +1,174 @@
+package activitypub
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Types of Activity Streams 2.0 handled
+const (
+	TypeCreate    = "Create"
+	TypeUpdate    = "Update"
+	TypeDelete    = "Delete"
+	TypeAnnounce  = "Announce"
+	TypeNote      = "Note"
+	TypeArticle   = "Article"
+	TypePage      = "Page"
+	TypeQuestion  = "Question"
+	TypeTombstone = "Tombstone"
+	TypeHashtag   = "Hashtag"
+)
+
+// Public is the (special) collection of the audience of public objects, per any of its forms.
+var Public = []string{"https://www.w3.org/ns/activitystreams#Public", "as:Public", "Public"}
+
+// Actor is the (Person, Service, ...) whose outbox is the source of a site.
+type Actor struct {
+	ID                string          `json:"id"`
+	Type              string          `json:"type"`
+	Name              string          `json:"name,omitempty"`
+	PreferredUsername string          `json:"preferredUsername,omitempty"`
+	Summary           string          `json:"summary,omitempty"` // HTML
+	URL               json.RawMessage `json:"url,omitempty"`
+	Icon              json.RawMessage `json:"icon,omitempty"`
+	Outbox            string          `json:"outbox"`
+}
+
+// Collection is an OrderedCollection (outbox), else a page thereof; either may embed its items.
+type Collection struct {
+	ID           string          `json:"id"`
+	Type         string          `json:"type"`
+	TotalItems   int             `json:"totalItems,omitempty"`
+	First        json.RawMessage `json:"first,omitempty"` // IRI or embedded page
+	Next         json.RawMessage `json:"next,omitempty"`  // IRI or link
+	OrderedItems []Activity      `json:"orderedItems,omitempty"`
+	Items        []Activity      `json:"items,omitempty"`
+}
+
+// Activity of an outbox; its object either embedded or referenced (IRI).
+type Activity struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Published string          `json:"published,omitempty"`
+	To        Audience        `json:"to,omitempty"`
+	Cc        Audience        `json:"cc,omitempty"`
+	Object    json.RawMessage `json:"object,omitempty"`
+}
+
+// Object is that (Note, Article, ...) of an activity.
+type Object struct {
+	ID           string          `json:"id"`
+	Type         string          `json:"type"`
+	URL          json.RawMessage `json:"url,omitempty"`
+	Name         string          `json:"name,omitempty"`    // Title of an Article
+	Summary      string          `json:"summary,omitempty"` // Content warning of a Note; summary of an Article
+	Content      string          `json:"content,omitempty"` // HTML
+	Sensitive    bool            `json:"sensitive,omitempty"`
+	InReplyTo    json.RawMessage `json:"inReplyTo,omitempty"`
+	Published    string          `json:"published,omitempty"`
+	Updated      string          `json:"updated,omitempty"`
+	Deleted      string          `json:"deleted,omitempty"` // Of a Tombstone
+	To           Audience        `json:"to,omitempty"`
+	Cc           Audience        `json:"cc,omitempty"`
+	Tag          []Tag           `json:"tag,omitempty"`
+	Attachment   []Attachment    `json:"attachment,omitempty"`
+	AttributedTo json.RawMessage `json:"attributedTo,omitempty"`
+}
+
+// Tag of an object; a Hashtag, Mention, or Emoji.
+type Tag struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Href string `json:"href,omitempty"`
+}
+
+// Attachment (media) of an object.
+type Attachment struct {
+	Type      string          `json:"type"` // Document, Image, Video, Audio
+	MediaType string          `json:"mediaType,omitempty"`
+	URL       json.RawMessage `json:"url"`
+	Name      string          `json:"name,omitempty"` // Alt text
+	Width     int             `json:"width,omitempty"`
+	Height    int             `json:"height,omitempty"`
+}
+
+// Audience (to, cc) is a list of IRIs, declared either as such or as one.
+type Audience []string
+
+// UnmarshalJSON decodes an audience of either form.
+func (a *Audience) UnmarshalJSON(bb []byte) error {
+	var s string
+	if err := json.Unmarshal(bb, &s); err == nil {
+		*a = Audience{s}
+		return nil
+	}
+	var ss []string
+	if err := json.Unmarshal(bb, &ss); err != nil {
+		return err
+	}
+	*a = ss
+	return nil
+}
+
+// public reports whether any of the audiences is the Public collection.
+func public(aa ...Audience) bool {
+	for _, a := range aa {
+		for _, iri := range a {
+			for _, p := range Public {
+				if iri == p {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Webfinger is the JRD (RFC 7033) of an account.
+type Webfinger struct {
+	Subject string `json:"subject"`
+	Links   []struct {
+		Rel  string `json:"rel"`
+		Type string `json:"type,omitempty"`
+		Href string `json:"href,omitempty"`
+	} `json:"links"`
+}
+
+// href returns the URL of a reference of any form: an IRI, a Link (or object) thereof,
+// or a list of either; that of text/html preferred. Empty if none.
+func href(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	type link struct {
+		Href      string          `json:"href"`
+		ID        string          `json:"id"`
+		URL       json.RawMessage `json:"url"`
+		MediaType string          `json:"mediaType"`
+	}
+	var l link
+	if err := json.Unmarshal(raw, &l); err == nil {
+		switch {
+		case l.Href != "":
+			return l.Href
+		case len(l.URL) > 0:
+			return href(l.URL)
+		}
+		return l.ID
+	}
+	var ll []json.RawMessage
+	if err := json.Unmarshal(raw, &ll); err != nil || len(ll) == 0 {
+		return ""
+	}
+	for _, r := range ll {
+		var l link
+		if err := json.Unmarshal(r, &l); err == nil && strings.HasPrefix(l.MediaType, "text/html") {
+			return l.Href
+		}
+	}
+	return href(ll[0])
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"github.com/imroc/req/v3"
+	"github.com/sempernow/kit/types/convert"
+)
+
+const HOSTED_ENDPT = "/c/hosted"
+
+// HostedChannel is a channel (mirror) of an externally-hosted site, and its owner.
+type HostedChannel struct {
+	UserHandle string `db:"handle" json:"user_handle,omitempty"` // users.handle
+	OwnerID    string `db:"owner_id" json:"owner_id,omitempty"`  // channels.owner_id
+	ChnID      string `db:"chn_id" json:"chn_id,omitempty"`      // channels.chn_id
+	ChnSlug    string `db:"chn_slug" json:"chn_slug,omitempty"`  // channels.slug
+	HostURL    string `db:"host_url" json:"host_url,omitempty"`  // channels.host_url
+	Error      string `db:"-" json:"error,omitempty"`
+}
+
+// GetHostedChannels makes token-authenticated GET request for all hosted (mirror) channels,
+// whose JSON list ([]HostedChannel) is the Response.Body.
+//
+//	Defaults: jwt (arg[0]): env.GetCache(client.CacheKeyTknPrefix + env.Client.User)
+func (env *Env) GetHostedChannels(arg ...string) *Response {
+	var (
+		endpt = env.BaseAPI + HOSTED_ENDPT
+		rtn   = Response{}
+		got   = HostedChannel{}
+		jwt   = convert.BytesToString(env.GetCache(CacheKeyTknPrefix + env.Client.User))
+	)
+	if len(arg) > 0 {
+		jwt = arg[0]
+	}
+	if jwt == "" {
+		rtn.Error = "missing token"
+		return &rtn
+	}
+
+	client := req.C().
+		SetUserAgent(env.UserAgent).
+		SetTimeout(env.Timeout)
+
+	rsp, err := client.R().
+		SetBearerAuthToken(jwt).
+		SetHeader("Accept", JSON).
+		SetError(&got).
+		Get(endpt)
+
+	if err != nil {
+		rtn.Error = err.Error()
+		return &rtn
+	}
+	rtn.Code = rsp.StatusCode
+
+	if rsp.IsError() {
+		rtn.Error = got.Error
+		if rtn.Error == "" {
+			rtn.Error = rsp.Status
+		}
+		return &rtn
+	}
+	rtn.Body = rsp.String()
+	return &rtn
+}
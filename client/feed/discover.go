@@ -0,0 +1,62 @@
+package feed
+
+import (
+	"strings"
+
+	"github.com/sempernow/uqc/client/mirror"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Types of feed advertised per <link rel="alternate" type="...">, in order of preference.
+var Types = []string{"application/atom+xml", "application/rss+xml"}
+
+// alternate returns the (absolute) URL of the feed advertised in the <head> of an HTML document,
+// resolved against its URL (base); empty if none. Comment feeds are ignored.
+func alternate(doc, base string) string {
+	found := map[string]string{}
+	z := html.NewTokenizer(strings.NewReader(doc))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return preferred(found, base)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			t := z.Token()
+			if t.DataAtom == atom.Body {
+				return preferred(found, base)
+			}
+			if t.DataAtom != atom.Link {
+				continue
+			}
+			var rel, typ, href, title string
+			for _, a := range t.Attr {
+				switch a.Key {
+				case "rel":
+					rel = strings.ToLower(a.Val)
+				case "type":
+					typ = strings.ToLower(a.Val)
+				case "href":
+					href = a.Val
+				case "title":
+					title = strings.ToLower(a.Val)
+				}
+			}
+			if rel != "alternate" || href == "" || strings.Contains(title, "comments") {
+				continue
+			}
+			if _, ok := found[typ]; !ok {
+				found[typ] = href
+			}
+		}
+	}
+}
+
+// preferred returns the found feed of the most preferred of Types.
+func preferred(found map[string]string, base string) string {
+	for _, typ := range Types {
+		if href, ok := found[typ]; ok {
+			return mirror.Resolve(base, href)
+		}
+	}
+	return ""
+}
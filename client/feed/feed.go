@@ -0,0 +1,362 @@
+// Package feed is the source adapter of sites publishing an RSS 2.0 (or 1.0) or Atom feed.
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
+	"golang.org/x/net/html/charset"
+)
+
+// DefaultPath is that of the feed of a site not declaring its feed (Site.FeedURL),
+// nor advertising it at its home page.
+const DefaultPath = "/feed"
+
+// MaxPages limits the pages (RFC 5005) of a feed fetched per run.
+const MaxPages = 10
+
+// Feed is the source of a site per its feed.
+// Each item maps to a message of its link (URI), title, content (else its summary, which is then the body),
+// categories and author (tags), and its date of update, else of publication.
+// Its RSS guid, else Atom id, is its identity per either strategy of Site.Identity other than URI.
+type Feed struct {
+	mirror.Adapter
+	Items []Item
+	page  *Page // First page; that describing the site.
+}
+
+// New returns the Feed source of a site.
+func New(env *client.Env, site *mirror.Site) *Feed {
+	return &Feed{Adapter: mirror.NewAdapter(env, site)}
+}
+
+// Describe merges the metadata of the feed into its Site record.
+func (f *Feed) Describe() {
+	p, err := f.first()
+	if err != nil {
+		f.Site.Error = err.Error()
+		return
+	}
+	site := f.Site
+	if p.Title != "" {
+		site.Name = html.UnescapeString(p.Title)
+	}
+	if p.Description != "" {
+		site.Description = html.UnescapeString(p.Description)
+	}
+	if p.Home != "" {
+		site.URL = p.Home
+		site.Home = p.Home
+	}
+}
+
+// Fetch retrieves the items of the feed updated since the checkpoint (since); all if zero.
+// Older pages, if advertised, are fetched until one is entirely older than since.
+func (f *Feed) Fetch(since time.Time) {
+	p, err := f.first()
+	for i := 0; i < MaxPages; i++ {
+		if err != nil {
+			f.Site.Error = err.Error()
+			return
+		}
+		fresh := 0
+		for _, item := range p.Items {
+			if since.IsZero() || item.time().IsZero() || item.time().After(since) {
+				f.Items = append(f.Items, item)
+				fresh++
+			}
+		}
+		if p.Next == "" || fresh == 0 {
+			return
+		}
+		p, err = f.get(p.Next)
+	}
+}
+
+// Messages maps the fetched items into Uqrate messages.
+func (f *Feed) Messages() []client.Message {
+	msgs := []client.Message{}
+	for _, item := range f.Items {
+		msg := f.ItemToMsg(&item)
+		if msg.ID == "" {
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// ItemToMsg denormalizes a feed item into a Uqrate message.
+func (f *Feed) ItemToMsg(item *Item) client.Message {
+	msg := client.Message{}
+	site := f.Site
+
+	msg.ChnID = site.ChnID
+	var err error
+	if msg.URI, err = mirror.LinkToURI(item.Link, site.HostURL); err != nil {
+		log.Printf("ERR : LinkToURI : item %s : %s\n", item.ID, err.Error())
+		return client.Message{}
+	}
+	msg.ID = f.ItemID(msg.URI, item.ID, item.ID)
+	if msg.ID == "" {
+		log.Printf("ERR : UUIDv5 fail : URI: %s .\n", msg.URI)
+		return client.Message{URI: msg.URI}
+	}
+
+	msg.Title = item.Title
+
+	// Feeds lacking full content carry only the summary, which is then the body.
+	excerpt, content := item.Summary, item.Content
+	if content == "" {
+		excerpt, content = "", item.Summary
+	}
+	msg.Body = content
+	if f.Cleaner != nil {
+		msg.Body = f.Cleaner(msg.Body)
+	}
+	msg.Summary = f.Summary(excerpt, content)
+
+	msg.Cats = append(msg.Cats, item.Categories...)
+	msg.Tags = mirror.AuthorTag(msg.Tags, item.Author)
+
+	mirror.Sanitize(msg.Cats)
+	mirror.Sanitize(msg.Tags)
+
+	msg.DateUpdate = mirror.DateUpdate(item.Updated, item.Published)
+
+	return msg
+}
+
+// URL returns that of the feed of the site: that declared (Site.FeedURL),
+// else that advertised at its home page, else that of DefaultPath.
+func (f *Feed) URL() string {
+	site := f.Site
+	if site.FeedURL == "" {
+		site.FeedURL = f.discover()
+	}
+	if site.FeedURL == "" {
+		site.FeedURL = strings.TrimSuffix(site.HostURL, "/") + DefaultPath
+	}
+	return site.FeedURL
+}
+
+// discover returns the URL of the feed advertised at the home page of the site, if any;
+// per its <link rel="alternate" type="application/(rss|atom)+xml"> tag.
+func (f *Feed) discover() string {
+	rsp := f.Env.Get(f.Site.HostURL, client.HTML)
+	f.Site.Pause()
+	if rsp.Error != "" {
+		return ""
+	}
+	return alternate(rsp.Body, f.Site.HostURL)
+}
+
+// first returns the first page of the feed; fetched once per Feed.
+func (f *Feed) first() (*Page, error) {
+	if f.page != nil {
+		return f.page, nil
+	}
+	p, err := f.get(f.URL())
+	if err != nil {
+		return nil, err
+	}
+	f.page = p
+	return p, nil
+}
+
+// get fetches and parses a page of the feed (url).
+func (f *Feed) get(url string) (*Page, error) {
+	rsp := f.Env.Get(url, client.XML)
+	f.Site.Pause()
+
+	f.Site.Status.Object = url
+	f.Site.Status.Code = rsp.Code
+
+	if rsp.Error != "" {
+		return nil, errors.New(rsp.Error)
+	}
+	if rsp.Body == "" {
+		return nil, errors.New("GET returned nothing")
+	}
+	p, err := Parse([]byte(rsp.Body))
+	if err != nil {
+		log.Printf("ERR : Parse feed @ %s : %s\n", url, err.Error())
+		return nil, err
+	}
+	if p.Next != "" {
+		p.Next = mirror.Resolve(url, p.Next)
+	}
+	return p, nil
+}
+
+// Parse decodes a feed document of either format (RSS or Atom) into its normalized page.
+// Decoding is lenient; HTML entities are tolerated.
+func Parse(bb []byte) (*Page, error) {
+	d := xml.NewDecoder(bytes.NewReader(bb))
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+	d.CharsetReader = charset.NewReaderLabel
+
+	for {
+		tkn, err := d.Token()
+		if err == io.EOF {
+			return nil, errors.New("not a feed : no root element")
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding feed")
+		}
+		root, ok := tkn.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(root.Name.Local) {
+		case "rss", "rdf":
+			doc := rssFeed{}
+			if err := d.DecodeElement(&doc, &root); err != nil {
+				return nil, errors.Wrap(err, "decoding RSS")
+			}
+			return doc.page(), nil
+		case "feed":
+			doc := atomFeed{}
+			if err := d.DecodeElement(&doc, &root); err != nil {
+				return nil, errors.Wrap(err, "decoding Atom")
+			}
+			return doc.page(), nil
+		}
+		return nil, errors.Errorf("not a feed : root element <%s>", root.Name.Local)
+	}
+}
+
+func (doc *rssFeed) page() *Page {
+	ch := doc.Channel
+	p := &Page{
+		Title:       strings.TrimSpace(ch.Title),
+		Description: strings.TrimSpace(ch.Description),
+		Home:        textLink(ch.Links),
+		Next:        relLink(ch.Links, "next"),
+	}
+	for _, it := range append(ch.Items, doc.Items...) {
+		item := Item{
+			ID:        strings.TrimSpace(it.GUID),
+			Link:      textLink(it.Links),
+			Title:     strings.TrimSpace(it.Title),
+			Summary:   strings.TrimSpace(it.Description),
+			Content:   strings.TrimSpace(it.Content),
+			Author:    strings.TrimSpace(it.Creator),
+			Published: mirror.ParseTime(it.PubDate),
+			Updated:   mirror.ParseTime(it.Updated),
+		}
+		if item.Author == "" {
+			item.Author = rssAuthor(it.Author)
+		}
+		if item.Published.IsZero() {
+			item.Published = mirror.ParseTime(it.Date)
+		}
+		if item.Link == "" && strings.HasPrefix(item.ID, "http") {
+			item.Link = item.ID
+		}
+		for _, c := range append(it.Categories, it.Subjects...) {
+			if c = strings.TrimSpace(c); c != "" {
+				item.Categories = append(item.Categories, c)
+			}
+		}
+		p.Items = append(p.Items, item)
+	}
+	return p
+}
+
+func (doc *atomFeed) page() *Page {
+	p := &Page{
+		Title:       doc.Title.text(),
+		Description: doc.Subtitle.text(),
+		Home:        relLink(doc.Links, "alternate"),
+		Next:        relLink(doc.Links, "next"),
+	}
+	for _, e := range doc.Entries {
+		item := Item{
+			ID:        strings.TrimSpace(e.ID),
+			Link:      relLink(e.Links, "alternate"),
+			Title:     e.Title.text(),
+			Summary:   e.Summary.html(),
+			Content:   e.Content.html(),
+			Published: mirror.ParseTime(e.Published),
+			Updated:   mirror.ParseTime(e.Updated),
+		}
+		if len(e.Authors) > 0 {
+			item.Author = strings.TrimSpace(e.Authors[0].Name)
+		} else {
+			item.Author = strings.TrimSpace(doc.Author.Name)
+		}
+		for _, c := range e.Categories {
+			name := c.Label
+			if name == "" {
+				name = c.Term
+			}
+			if name = strings.TrimSpace(name); name != "" {
+				item.Categories = append(item.Categories, name)
+			}
+		}
+		p.Items = append(p.Items, item)
+	}
+	return p
+}
+
+// text returns the (plain) text of an Atom text construct.
+func (t atomText) text() string {
+	if strings.ToLower(t.Type) == "text" || t.Type == "" {
+		return strings.TrimSpace(t.Text)
+	}
+	return mirror.HTMLToText(t.html())
+}
+
+// html returns the (HTML) content of an Atom text construct.
+func (t atomText) html() string {
+	switch strings.ToLower(t.Type) {
+	case "xhtml":
+		return strings.TrimSpace(t.Inner)
+	case "html", "text/html":
+		return strings.TrimSpace(t.Text)
+	}
+	return html.EscapeString(strings.TrimSpace(t.Text))
+}
+
+// textLink returns the first link rendered as text (RSS), else as href (Atom) of rel alternate.
+func textLink(ll []link) string {
+	for _, l := range ll {
+		if s := strings.TrimSpace(l.Text); s != "" {
+			return s
+		}
+	}
+	return relLink(ll, "alternate")
+}
+
+// relLink returns the href of the first link of rel; an absent rel is that of alternate.
+func relLink(ll []link, rel string) string {
+	for _, l := range ll {
+		r := l.Rel
+		if r == "" {
+			r = "alternate"
+		}
+		if r == rel && l.Href != "" {
+			return strings.TrimSpace(l.Href)
+		}
+	}
+	return ""
+}
+
+// rssAuthor returns the name of an RSS author, rendered as "email (name)".
+func rssAuthor(s string) string {
+	s = strings.TrimSpace(s)
+	if i, j := strings.Index(s, "("), strings.LastIndex(s, ")"); i > -1 && j > i {
+		return strings.TrimSpace(s[i+1 : j])
+	}
+	return s
+}
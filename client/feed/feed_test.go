@@ -0,0 +1,158 @@
+package feed
+
+import (
+	"testing"
+	"time"
+)
+
+const rssDoc = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/"
+	xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:atom="http://www.w3.org/2005/Atom">
+<channel>
+	<title>The Site &amp; Co</title>
+	<description>About&nbsp;it</description>
+	<link>https://x.com/</link>
+	<atom:link rel="self" href="https://x.com/feed/"/>
+	<atom:link rel="next" href="https://x.com/feed/?paged=2"/>
+	<item>
+		<title>A Post</title>
+		<link>https://x.com/a-post/</link>
+		<guid isPermaLink="false">https://x.com/?p=7</guid>
+		<description><![CDATA[<p>The excerpt.</p>]]></description>
+		<content:encoded><![CDATA[<p>The content.</p>]]></content:encoded>
+		<dc:creator>Jane Doe</dc:creator>
+		<category>News</category>
+		<category> </category>
+		<dc:subject>Go</dc:subject>
+		<pubDate>Mon, 11 Jul 2022 14:22:07 +0000</pubDate>
+	</item>
+	<item>
+		<title>Sans Link</title>
+		<guid>https://x.com/b-post/</guid>
+		<author>jd@x.com (John Doe)</author>
+		<dc:date>2022-07-12T08:00:00Z</dc:date>
+	</item>
+</channel>
+</rss>`
+
+const atomDoc = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title type="html">The &lt;em&gt;Site&lt;/em&gt;</title>
+	<subtitle>About it</subtitle>
+	<link href="https://x.com/"/>
+	<link rel="self" href="https://x.com/atom.xml"/>
+	<author><name>Jane Doe</name></author>
+	<entry>
+		<id>tag:x.com,2022:7</id>
+		<title>A Post</title>
+		<link rel="alternate" href="https://x.com/a-post/"/>
+		<link rel="replies" href="https://x.com/a-post/#comments"/>
+		<summary>Plain &amp; short</summary>
+		<content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>The content.</p></div></content>
+		<category term="go" label="Go"/>
+		<category term="news"/>
+		<published>2022-07-11T14:22:07Z</published>
+		<updated>2022-07-12T10:00:00+02:00</updated>
+	</entry>
+	<entry>
+		<id>tag:x.com,2022:8</id>
+		<title>B Post</title>
+		<link href="https://x.com/b-post/"/>
+		<author><name>John Doe</name></author>
+		<content type="html">&lt;p&gt;B&lt;/p&gt;</content>
+	</entry>
+</feed>`
+
+const rdfDoc = `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/">
+	<channel><title>The Site</title><link>https://x.com/</link></channel>
+	<item><title>A Post</title><link>https://x.com/a-post/</link></item>
+</rdf:RDF>`
+
+func TestParseRSS(t *testing.T) {
+	p, err := Parse([]byte(rssDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Title != "The Site & Co" || p.Description != "About it" || p.Home != "https://x.com/" {
+		t.Errorf("page : %q, %q, %q", p.Title, p.Description, p.Home)
+	}
+	if p.Next != "https://x.com/feed/?paged=2" {
+		t.Errorf("next : %q", p.Next)
+	}
+	if len(p.Items) != 2 {
+		t.Fatalf("items : %d, want 2", len(p.Items))
+	}
+	a := p.Items[0]
+	want := Item{
+		ID:         "https://x.com/?p=7",
+		Link:       "https://x.com/a-post/",
+		Title:      "A Post",
+		Summary:    "<p>The excerpt.</p>",
+		Content:    "<p>The content.</p>",
+		Author:     "Jane Doe",
+		Categories: []string{"News", "Go"},
+		Published:  time.Date(2022, 7, 11, 14, 22, 7, 0, time.UTC),
+	}
+	if a.ID != want.ID || a.Link != want.Link || a.Title != want.Title || a.Summary != want.Summary ||
+		a.Content != want.Content || a.Author != want.Author || !a.Published.Equal(want.Published) ||
+		len(a.Categories) != 2 || a.Categories[0] != "News" || a.Categories[1] != "Go" {
+		t.Errorf("item\n got: %+v\nwant: %+v", a, want)
+	}
+	b := p.Items[1]
+	if b.Link != "https://x.com/b-post/" || b.Author != "John Doe" ||
+		!b.Published.Equal(time.Date(2022, 7, 12, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("item sans link : %+v", b)
+	}
+}
+
+func TestParseAtom(t *testing.T) {
+	p, err := Parse([]byte(atomDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Title != "The Site" || p.Description != "About it" || p.Home != "https://x.com/" || p.Next != "" {
+		t.Errorf("page : %q, %q, %q, %q", p.Title, p.Description, p.Home, p.Next)
+	}
+	if len(p.Items) != 2 {
+		t.Fatalf("items : %d, want 2", len(p.Items))
+	}
+	a := p.Items[0]
+	if a.ID != "tag:x.com,2022:7" || a.Link != "https://x.com/a-post/" || a.Author != "Jane Doe" {
+		t.Errorf("item : %+v", a)
+	}
+	if a.Summary != "Plain &amp; short" {
+		t.Errorf("summary (text) : %q", a.Summary)
+	}
+	if a.Content != `<div xmlns="http://www.w3.org/1999/xhtml"><p>The content.</p></div>` {
+		t.Errorf("content (xhtml) : %q", a.Content)
+	}
+	if len(a.Categories) != 2 || a.Categories[0] != "Go" || a.Categories[1] != "news" {
+		t.Errorf("categories : %q", a.Categories)
+	}
+	if !a.Updated.Equal(time.Date(2022, 7, 12, 8, 0, 0, 0, time.UTC)) || !a.time().Equal(a.Updated) {
+		t.Errorf("updated : %v", a.Updated)
+	}
+	b := p.Items[1]
+	if b.Author != "John Doe" || b.Content != "<p>B</p>" || !b.time().IsZero() {
+		t.Errorf("item : %+v", b)
+	}
+}
+
+func TestParseRDF(t *testing.T) {
+	p, err := Parse([]byte(rdfDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Title != "The Site" || len(p.Items) != 1 || p.Items[0].Link != "https://x.com/a-post/" {
+		t.Errorf("page : %+v", p)
+	}
+}
+
+func TestParseNotFeed(t *testing.T) {
+	for _, doc := range []string{"", "<html><body></body></html>", "not xml"} {
+		if _, err := Parse([]byte(doc)); err == nil {
+			t.Errorf("Parse(%q) : want error", doc)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package feed
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Item is the format-normalized entry of a feed; that of either RSS (item) or Atom (entry).
+type Item struct {
+	ID         string    `json:"id,omitempty"` // RSS guid, else Atom id
+	Link       string    `json:"link,omitempty"`
+	Title      string    `json:"title,omitempty"`
+	Summary    string    `json:"summary,omitempty"` // HTML
+	Content    string    `json:"content,omitempty"` // HTML
+	Author     string    `json:"author,omitempty"`
+	Categories []string  `json:"categories,omitempty"`
+	Published  time.Time `json:"published,omitempty"`
+	Updated    time.Time `json:"updated,omitempty"`
+}
+
+// Page is the format-normalized document of a feed (page).
+type Page struct {
+	Title       string
+	Description string
+	Home        string
+	Next        string // URL of the next (older) page, if any (RFC 5005)
+	Items       []Item
+}
+
+// Namespaces of RSS extensions
+const (
+	NSContent = "http://purl.org/rss/1.0/modules/content/"
+	NSDC      = "http://purl.org/dc/elements/1.1/"
+	NSAtom    = "http://www.w3.org/2005/Atom"
+)
+
+// link is that of either format; RSS renders its URL as text, Atom as href attribute.
+type link struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// ----------------------------------------------------------------------------
+// RSS 2.0 (and 1.0, whose items are siblings of its channel)
+
+type rssFeed struct {
+	Channel rssChannel `xml:"channel"`
+	Items   []rssItem  `xml:"item"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Description string    `xml:"description"`
+	Links       []link    `xml:"link"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Links       []link   `xml:"link"`
+	GUID        string   `xml:"guid"`
+	Description string   `xml:"description"`
+	Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Author      string   `xml:"author"`
+	Categories  []string `xml:"category"`
+	Subjects    []string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+	PubDate     string   `xml:"pubDate"`
+	Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Updated     string   `xml:"http://www.w3.org/2005/Atom updated"`
+}
+
+// ----------------------------------------------------------------------------
+// Atom (RFC 4287)
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Title    atomText    `xml:"title"`
+	Subtitle atomText    `xml:"subtitle"`
+	Links    []link      `xml:"link"`
+	Author   atomPerson  `xml:"author"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      atomText       `xml:"title"`
+	Links      []link         `xml:"link"`
+	Summary    atomText       `xml:"summary"`
+	Content    atomText       `xml:"content"`
+	Authors    []atomPerson   `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+}
+
+// atomText is text of type text, html (escaped), or xhtml (inline markup).
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+// time returns that of the item's last update, else its publication; zero if neither.
+func (item *Item) time() time.Time {
+	if !item.Updated.IsZero() {
+		return item.Updated
+	}
+	return item.Published
+}
@@ -7,8 +7,26 @@ import (
 )
 
 // Get returns the *Response of a GET.
-// 	cType : HTML or JSON (default).
+//
+//	cType : HTML, XML, IMG, ACTIVITY or JSON (default).
 func (env *Env) Get(url, cType string) *Response {
+	return env.get(url, cType, nil)
+}
+
+// GetByBasic returns the *Response of a GET using Basic Auth (user, pass).
+//
+//	cType : HTML, XML, IMG, ACTIVITY or JSON (default).
+func (env *Env) GetByBasic(url, cType, user, pass string) *Response {
+	return env.get(url, cType, &basicAuth{user: user, pass: pass})
+}
+
+// basicAuth contains the credentials of a GET using Basic Auth.
+type basicAuth struct {
+	user, pass string
+}
+
+// get returns the *Response of a GET, authenticated per auth unless nil; see Get.
+func (env *Env) get(url, cType string, auth *basicAuth) *Response {
 
 	var rtn Response
 
@@ -17,9 +35,16 @@ func (env *Env) Get(url, cType string) *Response {
 		return &rtn
 	}
 
-	if strings.ToLower(cType) == "html" {
+	switch strings.ToLower(cType) {
+	case "html", HTML:
 		cType = HTML
-	} else {
+	case "xml", XML:
+		cType = XML
+	case "img", IMG:
+		cType = IMG
+	case "activity", ACTIVITY:
+		cType = ACTIVITY
+	default:
 		cType = JSON
 	}
 
@@ -27,16 +52,21 @@ func (env *Env) Get(url, cType string) *Response {
 		SetUserAgent(env.UserAgent).
 		SetTimeout(env.Timeout)
 
-	rsp, err := client.R().
+	r := client.R().
 		SetHeader("Accept", cType).
-		SetError(&rtn).
-		Get(url)
+		SetError(&rtn)
+	if auth != nil {
+		r.SetBasicAuth(auth.user, auth.pass)
+	}
+
+	rsp, err := r.Get(url)
 
 	if err != nil {
 		rtn.Error = err.Error()
 		return &rtn
 	}
 	rtn.Code = rsp.StatusCode
+	rtn.Header = rsp.Header
 
 	if rsp.IsError() {
 		rtn.Error = rsp.Status
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		user, pass, ok := r.BasicAuth()
+		if ok {
+			w.Write([]byte(r.Header.Get("Accept") + " " + user + ":" + pass))
+			return
+		}
+		w.Write([]byte(r.Header.Get("Accept")))
+	}))
+	defer srv.Close()
+	env := &Env{Client: Client{Timeout: 5 * time.Second}}
+
+	tests := []struct {
+		name string
+		rsp  *Response
+		want string
+		code int
+		err  bool
+	}{
+		{"json", env.Get(srv.URL, ""), JSON, 200, false},
+		{"img", env.Get(srv.URL, "img"), IMG, 200, false},
+		{"activity", env.Get(srv.URL, ACTIVITY), ACTIVITY, 200, false},
+		{"basic", env.GetByBasic(srv.URL, "xml", "u", "p"), XML + " u:p", 200, false},
+		{"basic img", env.GetByBasic(srv.URL, IMG, "u", "p"), IMG + " u:p", 200, false},
+		{"not found", env.Get(srv.URL+"/missing", ""), "", 404, true},
+		{"missing url", env.GetByBasic("", "", "u", "p"), "", 0, true},
+	}
+	for _, tt := range tests {
+		if tt.rsp.Body != tt.want || tt.rsp.Code != tt.code || (tt.rsp.Error != "") != tt.err {
+			t.Errorf("%s : %+v", tt.name, tt.rsp)
+		}
+	}
+}
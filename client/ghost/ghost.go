@@ -0,0 +1,244 @@
+// Package ghost is the source adapter of sites running Ghost, per its Content API.
+package ghost
+
+import (
+	"encoding/json"
+	"log"
+	neturl "net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sempernow/kit/types/convert"
+	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
+)
+
+// APIPath is that of the Content API of a Ghost site (HostURL), unless declared otherwise (Site.APIRoot).
+const APIPath = "/ghost/api/content"
+
+// Endpoints of the Content API
+const (
+	PostsURI    = "/posts/?include=tags,authors&order=updated_at%20desc"
+	SettingsURI = "/settings/"
+)
+
+// Limit is the number of posts per page; MaxPages limits the pages fetched per run.
+const (
+	Limit    = 50
+	MaxPages = 20
+)
+
+// VisibilityPublic is that of posts mirrored; others are gated to members.
+const VisibilityPublic = "public"
+
+// SkipVisibility is the reason a gated post is not mirrored.
+const SkipVisibility = "members-only"
+
+// Ghost is the source of a site per its Content API,
+// authenticated by its Content API key, referenced per site (Site.Auth; see mirror.Secret).
+// Each public post maps to a message of its URL (URI), title and HTML (prefixed by its feature image),
+// custom excerpt, primary tag (category), public tags and primary author (tags), and its date of update, else publication.
+// Its id (IdentityID), else UUID (IdentityGUID), is its identity per Site.Identity.
+type Ghost struct {
+	mirror.Adapter
+	Posts []Post
+}
+
+// New returns the Ghost source of a site.
+func New(env *client.Env, site *mirror.Site) *Ghost {
+	return &Ghost{Adapter: mirror.NewAdapter(env, site)}
+}
+
+// Describe merges the settings of the site (title, description, icon, ...) into its Site record.
+func (g *Ghost) Describe() {
+	bb, err := g.get(SettingsURI)
+	if err != nil {
+		g.Site.Error = err.Error()
+		return
+	}
+	s := Settings{}
+	if err := json.Unmarshal(bb, &s); err != nil {
+		g.Site.Error = err.Error()
+		log.Printf("ERR : Unmarshalling : %s\n", err.Error())
+		return
+	}
+	site := g.Site
+	site.Name = s.Settings.Title
+	site.Description = s.Settings.Description
+	if s.Settings.URL != "" {
+		site.URL = s.Settings.URL
+		site.Home = s.Settings.URL
+	}
+	if s.Settings.Timezone != "" {
+		site.TimezoneString = s.Settings.Timezone
+	}
+	site.Icon = g.abs(s.Settings.Icon)
+	if site.Icon == "" {
+		site.Icon = g.abs(s.Settings.Logo)
+	}
+}
+
+// Fetch retrieves the posts of the site updated since the checkpoint (since); all if zero.
+// Gated (non-public) posts are recorded at Site.Skipped instead.
+func (g *Ghost) Fetch(since time.Time) {
+	uri := PostsURI + "&limit=" + convert.IntToString(Limit)
+	if !since.IsZero() {
+		uri += "&filter=" + neturl.QueryEscape("updated_at:>'"+since.UTC().Format("2006-01-02 15:04:05")+"'")
+	}
+	for page := 1; page <= MaxPages; page++ {
+		bb, err := g.get(uri + "&page=" + convert.IntToString(page))
+		if err != nil {
+			g.Site.Error = err.Error()
+			return
+		}
+		rsp := Posts{}
+		if err := json.Unmarshal(bb, &rsp); err != nil {
+			g.Site.Error = err.Error()
+			log.Printf("ERR : Unmarshalling : %s\n", err.Error())
+			return
+		}
+		for _, post := range rsp.Posts {
+			if post.Visibility != "" && post.Visibility != VisibilityPublic {
+				log.Printf("INFO : SKIP post %s @ %s : %s\n", post.ID, g.Site.HostURL, SkipVisibility)
+				g.Site.Skipped = append(g.Site.Skipped, mirror.Skip{Link: post.URL, Reason: SkipVisibility})
+				continue
+			}
+			g.Posts = append(g.Posts, post)
+		}
+		if rsp.Meta.Pagination.Next == nil {
+			return
+		}
+	}
+}
+
+// Messages maps the fetched posts into Uqrate messages.
+func (g *Ghost) Messages() []client.Message {
+	msgs := []client.Message{}
+	for _, post := range g.Posts {
+		msg := g.PostToMsg(&post)
+		if msg.ID == "" {
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// PostToMsg denormalizes a Ghost post into a Uqrate message.
+func (g *Ghost) PostToMsg(post *Post) client.Message {
+	msg := client.Message{}
+	site := g.Site
+
+	msg.ChnID = site.ChnID
+	var err error
+	if msg.URI, err = mirror.LinkToURI(post.URL, site.HostURL); err != nil {
+		log.Printf("ERR : LinkToURI : post %s : %s\n", post.ID, err.Error())
+		return client.Message{}
+	}
+	msg.ID = g.ItemID(msg.URI, "ghost/"+post.ID, post.UUID)
+	if msg.ID == "" {
+		log.Printf("ERR : UUIDv5 fail : URI: %s .\n", msg.URI)
+		return client.Message{URI: msg.URI}
+	}
+
+	msg.Title = post.Title
+	msg.Body = post.HTML
+
+	// Prepend the feature image unless the body already renders it.
+	if src := g.abs(post.FeatureImage); src != "" && !mirror.Renders(msg.Body, src) {
+		msg.Body = mirror.Figure(src, post.FeatureImageAlt, 0, 0) + msg.Body
+	}
+	if g.Cleaner != nil {
+		msg.Body = g.Cleaner(msg.Body)
+	}
+
+	// Ghost synthesizes (excerpt) from content absent that of its author (custom_excerpt).
+	msg.Summary = g.Summary(post.CustomExcerpt, post.HTML)
+
+	if post.PrimaryTag != nil && post.PrimaryTag.Visibility != "internal" {
+		msg.Cats = []string{post.PrimaryTag.Name}
+	}
+	for _, tag := range post.Tags {
+		if tag.Visibility == "internal" || strings.HasPrefix(tag.Name, "#") {
+			continue
+		}
+		msg.Tags = append(msg.Tags, tag.Name)
+	}
+	if post.PrimaryAuthor != nil {
+		msg.Tags = mirror.AuthorTag(msg.Tags, post.PrimaryAuthor.Name)
+	} else if len(post.Authors) > 0 {
+		msg.Tags = mirror.AuthorTag(msg.Tags, post.Authors[0].Name)
+	}
+
+	mirror.Sanitize(msg.Cats)
+	mirror.Sanitize(msg.Tags)
+
+	msg.DateUpdate = mirror.DateUpdate(
+		toTime(post.UpdatedAt),
+		toTime(post.PublishedAt),
+		toTime(post.CreatedAt),
+	)
+
+	return msg
+}
+
+// get performs the GET of a Content API endpoint (uri), authenticated by the key of the site.
+func (g *Ghost) get(uri string) ([]byte, error) {
+	key, err := mirror.Secret(g.Env.NS, g.Site.Auth)
+	if err != nil {
+		return nil, errors.Wrap(err, "content api key")
+	}
+	sep := "?"
+	if strings.Contains(uri, "?") {
+		sep = "&"
+	}
+	url := g.root() + uri + sep + "key=" + neturl.QueryEscape(key)
+
+	rsp := g.Env.Get(url, client.JSON)
+	g.Site.Pause()
+
+	g.Site.Status.Object = uri
+	g.Site.Status.Code = rsp.Code
+
+	if rsp.Error != "" {
+		return nil, errors.New(rsp.Error)
+	}
+	if rsp.Body == "" {
+		return nil, errors.New("GET returned nothing")
+	}
+	return []byte(rsp.Body), nil
+}
+
+// root returns the (absolute) root of the Content API of the site.
+func (g *Ghost) root() string {
+	if g.Site.APIRoot != "" {
+		return strings.TrimSuffix(g.Site.APIRoot, "/")
+	}
+	return strings.TrimSuffix(g.Site.HostURL, "/") + APIPath
+}
+
+// abs returns the absolute URL of a (site-relative) reference; empty if none.
+func (g *Ghost) abs(ref string) string {
+	if ref == "" {
+		return ""
+	}
+	base, err := neturl.Parse(g.Site.HostURL)
+	if err != nil {
+		return ref
+	}
+	r, err := neturl.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return base.ResolveReference(r).String()
+}
+
+// toTime parses a Ghost (ISO 8601) timestamp into time (UTC); zero on fail.
+func toTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.UTC()
+}
@@ -0,0 +1,149 @@
+package ghost
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
+)
+
+const testChnID = "e2b3e5b4-1b0e-4c8e-8f1b-3c1b7e0e5a11"
+
+// Pages of posts; the first links the next.
+var testPosts = []string{`{
+	"posts": [
+		{
+			"id": "p1", "uuid": "u1", "title": "Featured", "url": "%[1]s/featured/",
+			"html": "<p>Body of the post.</p>", "custom_excerpt": "The excerpt.",
+			"feature_image": "/content/images/lead.jpg", "feature_image_alt": "Lead",
+			"visibility": "public",
+			"created_at": "2024-01-01T00:00:00.000Z", "published_at": "2024-01-02T00:00:00.000Z", "updated_at": "2024-01-03T10:00:00.000+02:00",
+			"primary_tag": {"name": "Tech", "visibility": "public"},
+			"tags": [{"name": "Tech"}, {"name": "#hidden", "visibility": "internal"}, {"name": "Go"}],
+			"primary_author": {"name": "Jane"}
+		},
+		{"id": "p2", "title": "Gated", "url": "%[1]s/gated/", "visibility": "members"}
+	],
+	"meta": {"pagination": {"page": 1, "pages": 2, "next": 2}}
+}`, `{
+	"posts": [
+		{
+			"id": "p3", "uuid": "u3", "title": "Inline", "url": "%[1]s/inline/",
+			"html": "<p>Lead image inline.</p><img src=\"%[1]s/content/images/size/w600/inline.jpg\">",
+			"feature_image": "%[1]s/content/images/size/w600/inline.jpg",
+			"published_at": "2024-02-01T00:00:00Z",
+			"primary_tag": {"name": "#internal", "visibility": "internal"},
+			"authors": [{"name": "Bob"}]
+		}
+	],
+	"meta": {"pagination": {"page": 2, "pages": 2, "next": null}}
+}`}
+
+func TestPostToMsg(t *testing.T) {
+	t.Setenv("TEST_WP_AUTH_GHOST", "thekey")
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != APIPath+"/posts/" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("key") != "thekey" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		page := 1
+		fmt.Sscan(r.URL.Query().Get("page"), &page)
+		if page < 1 || page > len(testPosts) {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, testPosts[page-1], srv.URL)
+	}))
+	t.Cleanup(srv.Close)
+
+	env := &client.Env{
+		NS:     "TEST",
+		Logger: log.New(io.Discard, "", 0),
+		Cache:  t.TempDir(),
+		Client: client.Client{Timeout: 5 * time.Second},
+	}
+	site := &mirror.Site{
+		UserHandle: "test", HostURL: srv.URL, ChnID: testChnID, Source: "ghost", Auth: "ghost",
+		RateLimit: mirror.Duration(time.Millisecond),
+	}
+	g := New(env, site)
+	g.Fetch(time.Time{})
+	if site.Error != "" {
+		t.Fatalf("Fetch : %s", site.Error)
+	}
+	if len(site.Skipped) != 1 || site.Skipped[0].Reason != SkipVisibility {
+		t.Errorf("skipped : got %+v, want the gated post", site.Skipped)
+	}
+
+	tests := []struct {
+		title   string
+		uri     string
+		body    string // Prefix
+		summary string
+		cats    []string
+		tags    []string
+		date    time.Time
+	}{
+		{
+			"Featured", "/featured/",
+			mirror.Figure(srv.URL+"/content/images/lead.jpg", "Lead", 0, 0) + "<p>Body",
+			"The excerpt.",
+			[]string{"Tech"}, []string{"Tech", "Go", "Jane"},
+			time.Date(2024, 1, 3, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			"Inline", "/inline/",
+			"<p>Lead image inline.</p>",
+			"Lead image inline.",
+			nil, []string{"Bob"},
+			time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	msgs := g.Messages()
+	if len(msgs) != len(tests) {
+		t.Fatalf("got %d messages, want %d", len(msgs), len(tests))
+	}
+	for i, tt := range tests {
+		msg := msgs[i]
+		if msg.Title != tt.title {
+			t.Errorf("%d : title %q, want %q", i, msg.Title, tt.title)
+			continue
+		}
+		if msg.URI != tt.uri {
+			t.Errorf("%s : uri %q, want %q", tt.title, msg.URI, tt.uri)
+		}
+		if msg.ChnID != testChnID || msg.ID != mirror.MessageID(testChnID, tt.uri) {
+			t.Errorf("%s : id %q of chn %q", tt.title, msg.ID, msg.ChnID)
+		}
+		if !strings.HasPrefix(msg.Body, tt.body) {
+			t.Errorf("%s : body %q, want prefix %q", tt.title, msg.Body, tt.body)
+		}
+		if msg.Summary != tt.summary {
+			t.Errorf("%s : summary %q, want %q", tt.title, msg.Summary, tt.summary)
+		}
+		if fmt.Sprint(msg.Cats) != fmt.Sprint(tt.cats) || fmt.Sprint(msg.Tags) != fmt.Sprint(tt.tags) {
+			t.Errorf("%s : cats %v, tags %v, want %v, %v", tt.title, msg.Cats, msg.Tags, tt.cats, tt.tags)
+		}
+		if !msg.DateUpdate.Equal(tt.date) {
+			t.Errorf("%s : date %v, want %v", tt.title, msg.DateUpdate, tt.date)
+		}
+	}
+
+	// Per identity of id, the message is keyed thereby, regardless of its URL.
+	site.Identity = mirror.IdentityID
+	if msg := g.PostToMsg(&g.Posts[0]); msg.ID != mirror.MessageID(testChnID, "ghost/p1") {
+		t.Errorf("identity id : got %q", msg.ID)
+	}
+}
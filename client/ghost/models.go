@@ -0,0 +1,70 @@
+package ghost
+
+// Posts is the response body of the posts endpoint of the Ghost Content API.
+type Posts struct {
+	Posts []Post `json:"posts"`
+	Meta  Meta   `json:"meta"`
+}
+
+// Post is that of the Ghost Content API (v5) per include=tags,authors.
+type Post struct {
+	ID              string   `json:"id"`
+	UUID            string   `json:"uuid,omitempty"`
+	Title           string   `json:"title,omitempty"`
+	Slug            string   `json:"slug,omitempty"`
+	HTML            string   `json:"html,omitempty"`
+	Excerpt         string   `json:"excerpt,omitempty"`
+	CustomExcerpt   string   `json:"custom_excerpt,omitempty"`
+	FeatureImage    string   `json:"feature_image,omitempty"`
+	FeatureImageAlt string   `json:"feature_image_alt,omitempty"`
+	Visibility      string   `json:"visibility,omitempty"` // public, members, paid, tiers
+	URL             string   `json:"url,omitempty"`
+	CreatedAt       string   `json:"created_at,omitempty"`
+	UpdatedAt       string   `json:"updated_at,omitempty"`
+	PublishedAt     string   `json:"published_at,omitempty"`
+	Tags            []Tag    `json:"tags,omitempty"`
+	Authors         []Author `json:"authors,omitempty"`
+	PrimaryAuthor   *Author  `json:"primary_author,omitempty"`
+	PrimaryTag      *Tag     `json:"primary_tag,omitempty"`
+}
+
+// Tag of a Post; those of visibility internal (#name) are never mirrored.
+type Tag struct {
+	ID         string `json:"id,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Slug       string `json:"slug,omitempty"`
+	Visibility string `json:"visibility,omitempty"` // public, internal
+}
+
+// Author of a Post
+type Author struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	Slug string `json:"slug,omitempty"`
+}
+
+// Meta of a (paged) response
+type Meta struct {
+	Pagination Pagination `json:"pagination"`
+}
+
+// Pagination of a response; Next is null at the last page.
+type Pagination struct {
+	Page  int  `json:"page"`
+	Limit int  `json:"limit"`
+	Pages int  `json:"pages"`
+	Total int  `json:"total"`
+	Next  *int `json:"next"`
+}
+
+// Settings is the response body of the settings endpoint of the Ghost Content API.
+type Settings struct {
+	Settings struct {
+		Title       string `json:"title,omitempty"`
+		Description string `json:"description,omitempty"`
+		Logo        string `json:"logo,omitempty"`
+		Icon        string `json:"icon,omitempty"`
+		URL         string `json:"url,omitempty"`
+		Timezone    string `json:"timezone,omitempty"`
+	} `json:"settings"`
+}
@@ -0,0 +1,260 @@
+// Package jsonfeed is the source adapter of sites publishing a JSON Feed (1.1, or 1.0),
+// as do many static-site generators (Hugo, Jekyll, Eleventy) and newsletter platforms.
+package jsonfeed
+
+import (
+	"encoding/json"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
+)
+
+// DefaultPath is that of the feed of a site not declaring its feed (Site.FeedURL).
+const DefaultPath = "/feed.json"
+
+// MaxPages limits the pages (next_url) of a feed fetched per run.
+const MaxPages = 20
+
+// JSONFeed is the source of a site per its JSON Feed.
+// Each item maps to a message of its url (else external_url, else id if a URL; URI), title,
+// content_html (else content_text, as paragraphs) prefixed by its image, summary, tags and author (tags),
+// and its date_modified, else date_published. Its id is its identity per either strategy of Site.Identity other than URI.
+type JSONFeed struct {
+	mirror.Adapter
+	Items []Item
+	doc   *Document // First page; that describing the site.
+}
+
+// New returns the JSONFeed source of a site.
+func New(env *client.Env, site *mirror.Site) *JSONFeed {
+	return &JSONFeed{Adapter: mirror.NewAdapter(env, site)}
+}
+
+// Describe merges the metadata of the feed into its Site record.
+func (f *JSONFeed) Describe() {
+	doc, err := f.first()
+	if err != nil {
+		f.Site.Error = err.Error()
+		return
+	}
+	site := f.Site
+	if doc.Title != "" {
+		site.Name = doc.Title
+	}
+	if doc.Description != "" {
+		site.Description = doc.Description
+	}
+	if doc.HomePageURL != "" {
+		site.URL = doc.HomePageURL
+		site.Home = doc.HomePageURL
+	}
+	if doc.Icon != "" {
+		site.Icon = mirror.Resolve(f.URL(), doc.Icon)
+	} else if doc.Favicon != "" {
+		site.Icon = mirror.Resolve(f.URL(), doc.Favicon)
+	}
+}
+
+// Fetch retrieves the items of the feed updated since the checkpoint (since); all if zero.
+// Pages (next_url) are followed until one is entirely older than since.
+func (f *JSONFeed) Fetch(since time.Time) {
+	doc, err := f.first()
+	url := f.URL()
+	for i := 0; i < MaxPages; i++ {
+		if err != nil {
+			f.Site.Error = err.Error()
+			return
+		}
+		fresh := 0
+		for _, item := range doc.Items {
+			t := itemTime(&item)
+			if since.IsZero() || t.IsZero() || t.After(since) {
+				f.Items = append(f.Items, item)
+				fresh++
+			}
+		}
+		if doc.NextURL == "" || fresh == 0 {
+			return
+		}
+		url = mirror.Resolve(url, doc.NextURL)
+		doc, err = f.get(url)
+	}
+}
+
+// Messages maps the fetched items into Uqrate messages.
+func (f *JSONFeed) Messages() []client.Message {
+	msgs := []client.Message{}
+	for _, item := range f.Items {
+		msg := f.ItemToMsg(&item)
+		if msg.ID == "" {
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// ItemToMsg denormalizes a JSON Feed item into a Uqrate message.
+func (f *JSONFeed) ItemToMsg(item *Item) client.Message {
+	msg := client.Message{}
+	site := f.Site
+
+	link := item.URL
+	if link == "" {
+		link = item.ExternalURL
+	}
+	if link == "" && strings.HasPrefix(item.ID, "http") {
+		link = item.ID
+	}
+	if link == "" {
+		log.Printf("ERR : item %s : missing url\n", item.ID)
+		return client.Message{}
+	}
+	msg.ChnID = site.ChnID
+	var err error
+	if msg.URI, err = mirror.LinkToURI(mirror.Resolve(f.URL(), link), site.HostURL); err != nil {
+		log.Printf("ERR : LinkToURI : item %s : %s\n", item.ID, err.Error())
+		return client.Message{}
+	}
+	msg.ID = f.ItemID(msg.URI, item.ID, item.ID)
+	if msg.ID == "" {
+		log.Printf("ERR : UUIDv5 fail : URI: %s .\n", msg.URI)
+		return client.Message{URI: msg.URI}
+	}
+
+	msg.Title = item.Title
+
+	content := item.ContentHTML
+	if content == "" {
+		content = textToHTML(item.ContentText)
+	}
+	msg.Body = content
+
+	// Prepend the (main) image unless the body already renders it.
+	if src := item.Image; src != "" && !mirror.Renders(msg.Body, src) {
+		msg.Body = mirror.Figure(mirror.Resolve(f.URL(), src), "", 0, 0) + msg.Body
+	}
+	if f.Cleaner != nil {
+		msg.Body = f.Cleaner(msg.Body)
+	}
+
+	// The summary of an item is plain text.
+	msg.Summary = f.Summary(html.EscapeString(item.Summary), content)
+
+	msg.Tags = append(msg.Tags, item.Tags...)
+	msg.Tags = mirror.AuthorTag(msg.Tags, f.author(item))
+
+	mirror.Sanitize(msg.Tags)
+
+	msg.DateUpdate = mirror.DateUpdate(itemTime(item))
+
+	return msg
+}
+
+// author returns the name of the (first) author of an item, else that of its feed.
+func (f *JSONFeed) author(item *Item) string {
+	aa := []Author{}
+	if item.Author != nil {
+		aa = append(aa, *item.Author)
+	}
+	aa = append(append(aa, item.Authors...), f.authors()...)
+	for _, a := range aa {
+		if a.Name != "" {
+			return a.Name
+		}
+	}
+	return ""
+}
+
+// authors returns those of the feed.
+func (f *JSONFeed) authors() []Author {
+	if f.doc == nil {
+		return nil
+	}
+	if f.doc.Author != nil {
+		return append([]Author{*f.doc.Author}, f.doc.Authors...)
+	}
+	return f.doc.Authors
+}
+
+// URL returns that of the feed of the site: that declared (Site.FeedURL), else that of DefaultPath.
+func (f *JSONFeed) URL() string {
+	site := f.Site
+	if site.FeedURL == "" {
+		site.FeedURL = strings.TrimSuffix(site.HostURL, "/") + DefaultPath
+	}
+	return site.FeedURL
+}
+
+// first returns the first page of the feed; fetched once per JSONFeed.
+func (f *JSONFeed) first() (*Document, error) {
+	if f.doc != nil {
+		return f.doc, nil
+	}
+	doc, err := f.get(f.URL())
+	if err != nil {
+		return nil, err
+	}
+	f.doc = doc
+	return doc, nil
+}
+
+// get fetches and decodes a page of the feed (url).
+func (f *JSONFeed) get(url string) (*Document, error) {
+	rsp := f.Env.Get(url, client.JSON)
+	f.Site.Pause()
+
+	f.Site.Status.Object = url
+	f.Site.Status.Code = rsp.Code
+
+	if rsp.Error != "" {
+		return nil, errors.New(rsp.Error)
+	}
+	if rsp.Body == "" {
+		return nil, errors.New("GET returned nothing")
+	}
+	doc, err := Parse([]byte(rsp.Body))
+	if err != nil {
+		log.Printf("ERR : Parse feed @ %s : %s\n", url, err.Error())
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Parse decodes a JSON Feed document (page) of either version (1.1 or 1.0).
+func Parse(bb []byte) (*Document, error) {
+	doc := Document{}
+	if err := json.Unmarshal(bb, &doc); err != nil {
+		return nil, errors.Wrap(err, "decoding JSON Feed")
+	}
+	if !strings.HasPrefix(doc.Version, "https://jsonfeed.org/version/") {
+		return nil, errors.Errorf("not a JSON Feed : version %q", doc.Version)
+	}
+	return &doc, nil
+}
+
+// itemTime returns that of the item's last update, else its publication (UTC); zero if neither.
+func itemTime(item *Item) time.Time {
+	for _, s := range []string{item.DateModified, item.DatePublished} {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+// textToHTML renders plain text as HTML paragraphs per blank line.
+func textToHTML(text string) string {
+	var b strings.Builder
+	for _, p := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			b.WriteString("<p>" + strings.ReplaceAll(html.EscapeString(p), "\n", "<br>") + "</p>")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,140 @@
+package jsonfeed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sempernow/uqc/client/mirror"
+)
+
+const testChnID = "d5750f33-a12d-4719-9600-94fcee80f487"
+
+const doc11 = `{
+	"version": "https://jsonfeed.org/version/1.1",
+	"title": "The Site",
+	"home_page_url": "https://x.com/",
+	"feed_url": "https://x.com/feed.json",
+	"next_url": "feed-2.json",
+	"icon": "/icon.png",
+	"authors": [{"name": "Jane Doe"}],
+	"items": [
+		{
+			"id": "7",
+			"url": "https://x.com/a-post/",
+			"title": "A Post",
+			"content_html": "<p>The content.</p>",
+			"summary": "Plain & short",
+			"image": "/a.jpg",
+			"date_published": "2022-07-11T14:22:07Z",
+			"date_modified": "2022-07-12T10:00:00+02:00",
+			"tags": ["Go", "News"]
+		},
+		{
+			"id": "https://x.com/b-post/",
+			"content_text": "First line\nsecond line.\n\nNext <paragraph>.",
+			"author": {"name": "John Doe"}
+		}
+	]
+}`
+
+func TestParse(t *testing.T) {
+	doc, err := Parse([]byte(doc11))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title != "The Site" || doc.NextURL != "feed-2.json" || len(doc.Items) != 2 || len(doc.Authors) != 1 {
+		t.Errorf("doc : %+v", doc)
+	}
+	for _, bad := range []string{
+		`{"title": "sans version", "items": []}`,
+		`{"version": "1.1", "items": []}`,
+		`<rss/>`,
+		``,
+	} {
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("Parse(%q) : want error", bad)
+		}
+	}
+	if _, err := Parse([]byte(`{"version": "https://jsonfeed.org/version/1", "items": []}`)); err != nil {
+		t.Errorf("version 1 : %s", err)
+	}
+}
+
+func TestItemTime(t *testing.T) {
+	tests := []struct {
+		item Item
+		want time.Time
+	}{
+		{Item{DateModified: "2022-07-12T10:00:00+02:00", DatePublished: "2022-07-11T14:22:07Z"}, time.Date(2022, 7, 12, 8, 0, 0, 0, time.UTC)},
+		{Item{DateModified: "yesterday", DatePublished: "2022-07-11T14:22:07Z"}, time.Date(2022, 7, 11, 14, 22, 7, 0, time.UTC)},
+		{Item{}, time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := itemTime(&tt.item); !got.Equal(tt.want) {
+			t.Errorf("itemTime(%+v) = %v, want %v", tt.item, got, tt.want)
+		}
+	}
+}
+
+func TestTextToHTML(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"", ""},
+		{"One.", "<p>One.</p>"},
+		{"One\ntwo.\r\n\r\nThree & <four>.\n\n\n", "<p>One<br>two.</p><p>Three &amp; &lt;four&gt;.</p>"},
+	}
+	for _, tt := range tests {
+		if got := textToHTML(tt.text); got != tt.want {
+			t.Errorf("textToHTML(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestItemToMsg(t *testing.T) {
+	doc, err := Parse([]byte(doc11))
+	if err != nil {
+		t.Fatal(err)
+	}
+	site := mirror.Site{
+		HostURL:  "https://x.com",
+		ChnID:    testChnID,
+		FeedURL:  "https://x.com/feed.json",
+		Cleaners: []string{mirror.CleanersNone},
+		Identity: mirror.IdentityID,
+	}
+	f := New(nil, &site)
+	f.doc = doc
+
+	a := f.ItemToMsg(&doc.Items[0])
+	if a.URI != "/a-post/" || a.ID != mirror.MessageID(testChnID, "7") || a.Title != "A Post" {
+		t.Errorf("msg : %s, %s, %s", a.URI, a.ID, a.Title)
+	}
+	if a.Body != mirror.Figure("https://x.com/a.jpg", "", 0, 0)+"<p>The content.</p>" {
+		t.Errorf("body : %q", a.Body)
+	}
+	if a.Summary != "Plain & short" {
+		t.Errorf("summary : %q", a.Summary)
+	}
+	if len(a.Tags) != 3 || a.Tags[0] != "Go" || a.Tags[2] != "JaneDoe" {
+		t.Errorf("tags : %q", a.Tags)
+	}
+	if !a.DateUpdate.Equal(time.Date(2022, 7, 12, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("date : %v", a.DateUpdate)
+	}
+
+	b := f.ItemToMsg(&doc.Items[1])
+	if b.URI != "/b-post/" || b.ID != mirror.MessageID(testChnID, "https://x.com/b-post/") {
+		t.Errorf("msg : %s, %s", b.URI, b.ID)
+	}
+	if b.Body != "<p>First line<br>second line.</p><p>Next &lt;paragraph&gt;.</p>" {
+		t.Errorf("body : %q", b.Body)
+	}
+	if len(b.Tags) != 1 || b.Tags[0] != "JohnDoe" {
+		t.Errorf("tags : %q", b.Tags)
+	}
+
+	if m := f.ItemToMsg(&Item{ID: "9"}); m.ID != "" {
+		t.Errorf("item sans link : %+v", m)
+	}
+}
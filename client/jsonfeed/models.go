@@ -0,0 +1,41 @@
+package jsonfeed
+
+// Document is a page of a JSON Feed (1.1, or 1.0); see https://jsonfeed.org/version/1.1 .
+type Document struct {
+	Version     string   `json:"version"`
+	Title       string   `json:"title,omitempty"`
+	HomePageURL string   `json:"home_page_url,omitempty"`
+	FeedURL     string   `json:"feed_url,omitempty"`
+	Description string   `json:"description,omitempty"`
+	NextURL     string   `json:"next_url,omitempty"`
+	Icon        string   `json:"icon,omitempty"`
+	Favicon     string   `json:"favicon,omitempty"`
+	Authors     []Author `json:"authors,omitempty"`
+	Author      *Author  `json:"author,omitempty"` // 1.0
+	Items       []Item   `json:"items"`
+}
+
+// Item of a JSON Feed
+type Item struct {
+	ID            string   `json:"id"`
+	URL           string   `json:"url,omitempty"`
+	ExternalURL   string   `json:"external_url,omitempty"`
+	Title         string   `json:"title,omitempty"`
+	ContentHTML   string   `json:"content_html,omitempty"`
+	ContentText   string   `json:"content_text,omitempty"`
+	Summary       string   `json:"summary,omitempty"`
+	Image         string   `json:"image,omitempty"`
+	BannerImage   string   `json:"banner_image,omitempty"`
+	DatePublished string   `json:"date_published,omitempty"`
+	DateModified  string   `json:"date_modified,omitempty"`
+	Authors       []Author `json:"authors,omitempty"`
+	Author        *Author  `json:"author,omitempty"` // 1.0
+	Tags          []string `json:"tags,omitempty"`
+}
+
+// Author of a feed or item
+type Author struct {
+	Name   string `json:"name,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Avatar string `json:"avatar,omitempty"`
+}
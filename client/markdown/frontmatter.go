@@ -0,0 +1,272 @@
+package markdown
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sempernow/uqc/client/mirror"
+	"github.com/sempernow/uqc/client/yaml"
+)
+
+// Delimiters of front matter
+const (
+	DelimYAML = "---"
+	DelimTOML = "+++"
+)
+
+// Matter is the front matter of a Markdown file (Hugo/Jekyll style).
+type Matter struct {
+	Title      string    `json:"title,omitempty"`
+	Slug       string    `json:"slug,omitempty"`
+	Summary    string    `json:"summary,omitempty"`
+	Author     string    `json:"author,omitempty"`
+	Date       time.Time `json:"date,omitempty"`
+	Lastmod    time.Time `json:"lastmod,omitempty"`
+	Tags       []string  `json:"tags,omitempty"`
+	Categories []string  `json:"categories,omitempty"`
+	Image      string    `json:"image,omitempty"`
+	Draft      bool      `json:"draft,omitempty"`
+}
+
+// Split separates the front matter of a Markdown document (src) from its body,
+// parsing that of YAML (---) or TOML (+++) delimiters. Either is parsed per its common subset:
+// top-level scalars (strings, numbers, booleans, dates; of YAML also block scalars) and lists thereof
+// (inline or block); nested maps (tables) are ignored. A document lacking front matter returns its zero value.
+func Split(src string) (Matter, string, error) {
+	m := Matter{}
+	src = strings.TrimPrefix(strings.ReplaceAll(src, "\r\n", "\n"), "\ufeff")
+	delim := ""
+	switch {
+	case strings.HasPrefix(src, DelimYAML+"\n"):
+		delim = DelimYAML
+	case strings.HasPrefix(src, DelimTOML+"\n"):
+		delim = DelimTOML
+	default:
+		return m, src, nil
+	}
+	rest := src[len(delim)+1:]
+	end := strings.Index(rest, "\n"+delim)
+	if strings.HasPrefix(rest, delim) {
+		end = -1
+	}
+	if end < 0 && !strings.HasPrefix(rest, delim) {
+		return m, src, errors.New("unterminated front matter")
+	}
+	head, body := "", ""
+	if end < 0 {
+		body = strings.TrimPrefix(rest[len(delim):], "\n")
+	} else {
+		head = rest[:end]
+		body = rest[end+1+len(delim):]
+		if i := strings.Index(body, "\n"); i > -1 {
+			body = body[i+1:]
+		} else {
+			body = ""
+		}
+	}
+	var (
+		vv  map[string]interface{}
+		err error
+	)
+	if delim == DelimYAML {
+		vv, err = parseYAML(head)
+	} else {
+		vv, err = parseTOML(head)
+	}
+	if err != nil {
+		return m, body, err
+	}
+	m.Title = scalar(vv["title"])
+	m.Slug = scalar(vv["slug"])
+	m.Summary = first(scalar(vv["summary"]), scalar(vv["description"]), scalar(vv["excerpt"]))
+	m.Author = first(scalar(vv["author"]), scalar(vv["authors"]))
+	m.Image = first(scalar(vv["image"]), scalar(vv["featured_image"]), scalar(vv["cover"]))
+	m.Date = mirror.ParseTime(first(scalar(vv["date"]), scalar(vv["publishdate"]), scalar(vv["published"])))
+	m.Lastmod = mirror.ParseTime(first(scalar(vv["lastmod"]), scalar(vv["updated"]), scalar(vv["modified"])))
+	m.Tags = strs(vv["tags"])
+	m.Categories = strs(vv["categories"])
+	m.Draft, _ = strconv.ParseBool(scalar(vv["draft"]))
+	if p, err := strconv.ParseBool(scalar(vv["published"])); err == nil && !p {
+		m.Draft = true
+	}
+	return m, body, nil
+}
+
+// parseYAML parses the YAML of front matter (see yaml.Parse) into its top-level values; string or []string.
+func parseYAML(head string) (map[string]interface{}, error) {
+	vv := map[string]interface{}{}
+	doc, err := yaml.Parse(head)
+	if err == yaml.ErrEmpty {
+		return vv, nil
+	}
+	if err != nil {
+		return vv, errors.Wrap(err, "malformed YAML front matter")
+	}
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return vv, errors.New("malformed YAML front matter : not a mapping")
+	}
+	for k, v := range m {
+		k = strings.ToLower(k)
+		switch x := v.(type) {
+		case string:
+			vv[k] = x
+		case []interface{}:
+			ss := []string{}
+			for _, el := range x {
+				if s, ok := el.(string); ok && s != "" {
+					ss = append(ss, s)
+				}
+			}
+			vv[k] = ss
+		}
+	}
+	return vv, nil
+}
+
+// parseTOML parses the (subset of) TOML of front matter into its top-level values; string or []string.
+func parseTOML(head string) (map[string]interface{}, error) {
+	vv := map[string]interface{}{}
+	lines := strings.Split(head, "\n")
+	for n := 0; n < len(lines); n++ {
+		t := strings.TrimSpace(lines[n])
+		if t == "" || strings.HasPrefix(t, "#") {
+			continue
+		}
+		if strings.HasPrefix(t, "[") {
+			break // Tables (nested maps) follow top-level keys.
+		}
+		kv := strings.SplitN(t, "=", 2)
+		if len(kv) != 2 {
+			return vv, errors.Errorf("malformed TOML front matter at line %d : %s", n+1, t)
+		}
+		k, v := strings.ToLower(strings.TrimSpace(kv[0])), stripComment(strings.TrimSpace(kv[1]))
+		if strings.HasPrefix(v, "[") {
+			for !strings.HasSuffix(v, "]") && n+1 < len(lines) {
+				n++
+				v += " " + stripComment(strings.TrimSpace(lines[n]))
+			}
+			vv[unquote(k)] = inlineList(v)
+			continue
+		}
+		vv[unquote(k)] = unquote(v)
+	}
+	return vv, nil
+}
+
+// inlineList parses a list of the form [a, "b", 'c'].
+func inlineList(v string) []string {
+	v = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(v), "["), "]")
+	ss := []string{}
+	for _, s := range splitList(v) {
+		if s = unquote(strings.TrimSpace(s)); s != "" {
+			ss = append(ss, s)
+		}
+	}
+	return ss
+}
+
+// splitList splits on commas not quoted.
+func splitList(v string) []string {
+	var (
+		ss    []string
+		quote rune
+		cur   strings.Builder
+	)
+	for _, r := range v {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ',':
+			ss = append(ss, cur.String())
+			cur.Reset()
+			continue
+		}
+		cur.WriteRune(r)
+	}
+	return append(ss, cur.String())
+}
+
+// unquote a scalar of either quote style.
+func unquote(s string) string {
+	if len(s) > 1 {
+		switch {
+		case s[0] == '"' && s[len(s)-1] == '"':
+			if u, err := strconv.Unquote(s); err == nil {
+				return u
+			}
+			return s[1 : len(s)-1]
+		case s[0] == '\'' && s[len(s)-1] == '\'':
+			return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+		}
+	}
+	return s
+}
+
+// stripComment removes a trailing (unquoted) comment of a value.
+func stripComment(v string) string {
+	var (
+		quote  rune
+		escape bool
+	)
+	for i, r := range v {
+		switch {
+		case escape:
+			escape = false
+		case quote == '"' && r == '\\':
+			escape = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || v[i-1] == ' ' || v[i-1] == '\t'):
+			return strings.TrimSpace(v[:i])
+		}
+	}
+	return v
+}
+
+// scalar returns a value as string; the first of a list.
+func scalar(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case []string:
+		if len(x) > 0 {
+			return x[0]
+		}
+	}
+	return ""
+}
+
+// strs returns a value as list; a scalar as that of one, or of its comma-delimited elements.
+func strs(v interface{}) []string {
+	switch x := v.(type) {
+	case []string:
+		return x
+	case string:
+		if x == "" {
+			return nil
+		}
+		return inlineList(x)
+	}
+	return nil
+}
+
+// first returns the first non-empty of ss.
+func first(ss ...string) string {
+	for _, s := range ss {
+		if s = strings.TrimSpace(s); s != "" {
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+package markdown
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSplitYAML(t *testing.T) {
+	src := "\ufeff---\r\n" +
+		"title: \"A \\\"Quoted\\\" Post\" # comment\r\n" +
+		"slug: a-post\r\n" +
+		"description: 'It''s short'\r\n" +
+		"summary: >\r\n  Folded # kept\r\n  summary.\r\n" +
+		"date: 2022-07-11T14:22:07Z\r\n" +
+		"tags: [Go, \"a, b\", 'News']\r\n" +
+		"categories:\r\n" +
+		"  - One\r\n" +
+		"  - \"Two\"\r\n" +
+		"params:\r\n" +
+		"  nested: ignored\r\n" +
+		"draft: false\r\n" +
+		"---\r\n" +
+		"# Body\r\n"
+	m, body, err := Split(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Title != `A "Quoted" Post` || m.Slug != "a-post" || m.Summary != "Folded # kept summary." || m.Draft {
+		t.Errorf("matter : %+v", m)
+	}
+	if !m.Date.Equal(time.Date(2022, 7, 11, 14, 22, 7, 0, time.UTC)) || !m.Lastmod.IsZero() {
+		t.Errorf("dates : %v, %v", m.Date, m.Lastmod)
+	}
+	if len(m.Tags) != 3 || m.Tags[0] != "Go" || m.Tags[1] != "a, b" || m.Tags[2] != "News" {
+		t.Errorf("tags : %q", m.Tags)
+	}
+	if len(m.Categories) != 2 || m.Categories[0] != "One" || m.Categories[1] != "Two" {
+		t.Errorf("categories : %q", m.Categories)
+	}
+	if body != "# Body\n" {
+		t.Errorf("body : %q", body)
+	}
+}
+
+func TestSplitTOML(t *testing.T) {
+	src := "+++\n" +
+		"title = \"A Post\"\n" +
+		"lastmod = 2022-07-12\n" +
+		"tags = [\n  \"Go\", # first\n  \"News\",\n]\n" +
+		"author = \"Jane Doe\"\n" +
+		"published = false\n" +
+		"[params]\n" +
+		"title = \"ignored\"\n" +
+		"+++\n" +
+		"Body"
+	m, body, err := Split(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Title != "A Post" || m.Author != "Jane Doe" || !m.Draft {
+		t.Errorf("matter : %+v", m)
+	}
+	if !m.Lastmod.Equal(time.Date(2022, 7, 12, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("lastmod : %v", m.Lastmod)
+	}
+	if len(m.Tags) != 2 || m.Tags[0] != "Go" || m.Tags[1] != "News" {
+		t.Errorf("tags : %q", m.Tags)
+	}
+	if body != "Body" {
+		t.Errorf("body : %q", body)
+	}
+}
+
+func TestSplitSans(t *testing.T) {
+	tests := []struct {
+		name, src, body string
+		err             bool
+	}{
+		{"none", "# Title\n---\n", "# Title\n---\n", false},
+		{"empty", "---\n---\nBody", "Body", false},
+		{"unterminated", "---\ntitle: x\n", "---\ntitle: x\n", true},
+		{"malformed", "---\nnot a pair\n---\nBody", "Body", true},
+		{"scalar tags", "---\ntags: Go, News\n---\n", "", false},
+	}
+	for _, tt := range tests {
+		m, body, err := Split(tt.src)
+		if (err != nil) != tt.err || body != tt.body {
+			t.Errorf("%s : body %q, err %v", tt.name, body, err)
+		}
+		if tt.name == "scalar tags" && (len(m.Tags) != 2 || m.Tags[1] != "News") {
+			t.Errorf("%s : %q", tt.name, m.Tags)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+// Package markdown is the source adapter of a (local) directory of Markdown files (Hugo/Jekyll style),
+// each of YAML or TOML front matter, published into a Uqrate channel.
+package markdown
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"html"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
+)
+
+// Extensions of Markdown files
+var Extensions = []string{".md", ".markdown"}
+
+// IndexFile is that of the (optional) front matter describing the channel (Hugo convention).
+const IndexFile = "_index.md"
+
+// SuffixHashes is that of the cache key of the content hashes of the files upserted per site.
+const SuffixHashes = "_md_hashes.json"
+
+// SkipDraft is the reason a draft is not published.
+const SkipDraft = "draft"
+
+// Hashes maps the slug of each file upserted to the (SHA-256) hash of its content.
+type Hashes map[string]string
+
+// Doc is a Markdown file, parsed and rendered.
+type Doc struct {
+	Path    string    `json:"path"`
+	Slug    string    `json:"slug"`
+	Hash    string    `json:"hash"`
+	Matter  Matter    `json:"matter"`
+	HTML    string    `json:"html"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// Markdown is the source of a site per its directory (Site.Dir) of Markdown files.
+// Only files whose content changed since last upserted are fetched (see Commit).
+// Each file maps to a message of its slug (URI; see slug), title (else its first heading), HTML prefixed by its image,
+// summary, categories, tags and author (tags), and its lastmod, else date, else time the file was modified.
+// Its slug is its identity, regardless of Site.Identity.
+type Markdown struct {
+	mirror.Adapter
+	Docs   []Doc
+	hashes Hashes
+}
+
+// New returns the Markdown source of a site.
+func New(env *client.Env, site *mirror.Site) *Markdown {
+	return &Markdown{Adapter: mirror.NewAdapter(env, site)}
+}
+
+// Describe merges the front matter of the index file of the directory (title, description) into its Site record.
+func (md *Markdown) Describe() {
+	site := md.Site
+	bb, err := os.ReadFile(filepath.Join(site.Dir, IndexFile))
+	if err != nil {
+		return
+	}
+	m, _, err := Split(string(bb))
+	if err != nil {
+		site.Error = err.Error()
+		return
+	}
+	if m.Title != "" {
+		site.Name = m.Title
+	}
+	if m.Summary != "" {
+		site.Description = m.Summary
+	}
+}
+
+// Fetch reads and renders those Markdown files of the directory whose content changed since last upserted.
+// The checkpoint (since) is of no regard; content hashes are. Drafts are recorded at Site.Skipped.
+func (md *Markdown) Fetch(since time.Time) {
+	site := md.Site
+	if site.Dir == "" {
+		site.Error = "missing dir"
+		return
+	}
+	md.hashes = md.loadHashes()
+	unchanged := 0
+	err := filepath.Walk(site.Dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name := info.Name()
+		if info.IsDir() {
+			if path != site.Dir && strings.HasPrefix(name, ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".") || !isMarkdown(name) {
+			return nil
+		}
+		bb, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		m, body, err := Split(string(bb))
+		if err != nil {
+			log.Printf("ERR : front matter @ %s : %s\n", path, err.Error())
+			return nil
+		}
+		doc := Doc{
+			Path:    path,
+			Slug:    slug(site.Dir, path, m.Slug),
+			Hash:    hash(bb),
+			Matter:  m,
+			ModTime: info.ModTime().UTC(),
+		}
+		if m.Draft {
+			site.Skipped = append(site.Skipped, mirror.Skip{Link: path, Reason: SkipDraft})
+			return nil
+		}
+		if md.hashes[doc.Slug] == doc.Hash {
+			unchanged++
+			return nil
+		}
+		doc.HTML = Render(body)
+		md.Docs = append(md.Docs, doc)
+		return nil
+	})
+	if err != nil {
+		site.Error = err.Error()
+	}
+	sort.SliceStable(md.Docs, func(i, j int) bool { return md.Docs[i].Slug < md.Docs[j].Slug })
+	log.Printf("INFO : markdown @ %s : changed: %d : unchanged: %d\n", site.Dir, len(md.Docs), unchanged)
+}
+
+// Messages maps the fetched files into Uqrate messages.
+func (md *Markdown) Messages() []client.Message {
+	msgs := []client.Message{}
+	for _, doc := range md.Docs {
+		msg := md.DocToMsg(&doc)
+		if msg.ID == "" {
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// DocToMsg denormalizes a Markdown file into a Uqrate message; its id per channel and slug.
+func (md *Markdown) DocToMsg(doc *Doc) client.Message {
+	msg := client.Message{}
+	site := md.Site
+
+	msg.ChnID = site.ChnID
+	msg.URI = "/" + doc.Slug + "/"
+	msg.ID = mirror.MessageID(msg.ChnID, doc.Slug)
+	if msg.ID == "" {
+		log.Printf("ERR : UUIDv5 fail : slug: %s .\n", doc.Slug)
+		return client.Message{URI: msg.URI}
+	}
+
+	m := doc.Matter
+	msg.Title = m.Title
+	if msg.Title == "" {
+		msg.Title = heading(doc.HTML)
+	}
+	if msg.Title == "" {
+		msg.Title = doc.Slug
+	}
+	msg.Body = doc.HTML
+	if m.Image != "" && !mirror.Renders(msg.Body, m.Image) {
+		msg.Body = mirror.Figure(m.Image, msg.Title, 0, 0) + msg.Body
+	}
+	if md.Cleaner != nil {
+		msg.Body = md.Cleaner(msg.Body)
+	}
+
+	// The summary of front matter is plain text.
+	msg.Summary = md.Summary(html.EscapeString(m.Summary), doc.HTML)
+
+	msg.Cats = append(msg.Cats, m.Categories...)
+	msg.Tags = append(msg.Tags, m.Tags...)
+	msg.Tags = mirror.AuthorTag(msg.Tags, m.Author)
+
+	mirror.Sanitize(msg.Cats)
+	mirror.Sanitize(msg.Tags)
+
+	msg.DateUpdate = mirror.DateUpdate(m.Lastmod, m.Date, doc.ModTime)
+
+	return msg
+}
+
+// Commit records the content hash of each file whose message was upserted,
+// so it is not fetched anew unless changed.
+func (md *Markdown) Commit(upserted []client.Message) {
+	if md.hashes == nil {
+		md.hashes = md.loadHashes()
+	}
+	ids := map[string]bool{}
+	for _, msg := range upserted {
+		ids[msg.ID] = true
+	}
+	n := 0
+	for _, doc := range md.Docs {
+		if ids[mirror.MessageID(md.Site.ChnID, doc.Slug)] {
+			md.hashes[doc.Slug] = doc.Hash
+			n++
+		}
+	}
+	if n == 0 {
+		return
+	}
+	bb, err := json.Marshal(md.hashes)
+	if err != nil {
+		log.Printf("ERR : Marshalling : %s\n", err.Error())
+		return
+	}
+	if err := md.Env.SetCache(md.hashesKey(), string(bb)); err != nil {
+		log.Printf("ERR : SetCache @ %s : %s\n", md.hashesKey(), err.Error())
+	}
+}
+
+// hashesKey returns the cache key of the content hashes of the site.
+func (md *Markdown) hashesKey() string {
+	return md.Site.UserHandle + SuffixHashes
+}
+
+// loadHashes reads the content hashes of the site from cache.
+func (md *Markdown) loadHashes() Hashes {
+	hh := Hashes{}
+	bb := md.Env.GetCache(md.hashesKey())
+	if len(bb) == 0 {
+		return hh
+	}
+	if err := json.Unmarshal(bb, &hh); err != nil {
+		log.Printf("ERR : Unmarshalling : %s\n", err.Error())
+	}
+	return hh
+}
+
+func isMarkdown(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range Extensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// nonSlug matches runs of characters invalid in a slug; any but (Unicode) letters and digits.
+var nonSlug = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// slug returns that of a file (path) of the directory (dir); its path relative thereto sans extension,
+// whose last element is that declared (front matter), else that of the file name, else of its folder
+// if that of a page bundle (index.md). Each element is lowercased, and runs of nonSlug are hyphenated, e.g.,
+//
+//	"posts/Café au Lait.md" => "posts/café-au-lait"
+//	"posts/2022/bundle/index.md" => "posts/2022/bundle"
+func slug(dir, path, declared string) string {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filepath.Base(path)
+	}
+	ss := strings.Split(filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel))), "/")
+	if len(ss) > 1 && ss[len(ss)-1] == "index" {
+		ss = ss[:len(ss)-1]
+	}
+	if declared != "" {
+		ss[len(ss)-1] = declared
+	}
+	els := []string{}
+	for _, s := range ss {
+		if s = strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(s), "-"), "-"); s != "" {
+			els = append(els, s)
+		}
+	}
+	if len(els) == 0 {
+		return hash([]byte(rel))[:12]
+	}
+	return strings.Join(els, "/")
+}
+
+func hash(bb []byte) string {
+	sum := sha256.Sum256(bb)
+	return hex.EncodeToString(sum[:])
+}
+
+// reH1 matches the first (rendered) level-1 heading.
+var reH1 = regexp.MustCompile(`<h1>(.*?)</h1>`)
+
+// heading returns the text of the first level-1 heading of rendered HTML; empty if none.
+func heading(s string) string {
+	m := reH1.FindStringSubmatch(s)
+	if m == nil {
+		return ""
+	}
+	return mirror.HTMLToText(m[1])
+}
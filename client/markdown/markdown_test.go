@@ -0,0 +1,30 @@
+package markdown
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSlug(t *testing.T) {
+	dir := filepath.FromSlash("/site/content")
+	tests := []struct {
+		path, declared, want string
+	}{
+		{"/site/content/hello-world.md", "", "hello-world"},
+		{"/site/content/posts/Café au Lait.md", "", "posts/café-au-lait"},
+		{"/site/content/posts/2022/bundle/index.md", "", "posts/2022/bundle"},
+		{"/site/content/index.md", "", "index"},
+		{"/site/content/posts/a.md", "The New Slug!", "posts/the-new-slug"},
+		{"/site/content/日本語/記事.md", "", "日本語/記事"},
+		{"/elsewhere/Out There.md", "", "out-there"},
+		{"/site/content/posts/ !!.md", "", "posts"},
+	}
+	for _, tt := range tests {
+		if got := slug(dir, filepath.FromSlash(tt.path), tt.declared); got != tt.want {
+			t.Errorf("slug(%q, %q) = %q, want %q", tt.path, tt.declared, got, tt.want)
+		}
+	}
+	if got := slug(dir, filepath.FromSlash("/site/content/!!.md"), ""); len(got) != 12 {
+		t.Errorf("slug of punctuation : %q, want hash", got)
+	}
+}
@@ -0,0 +1,346 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Render renders Markdown (CommonMark core, plus ~~strikethrough~~) as HTML:
+// ATX and setext headings, paragraphs, fenced and indented code, blockquotes,
+// (nested) lists, thematic breaks, raw HTML blocks, and inline emphasis, code, links and images.
+// Reference-style links and tables are not supported; such render as text.
+func Render(src string) string {
+	src = strings.ReplaceAll(strings.ReplaceAll(src, "\r\n", "\n"), "\t", "    ")
+	return strings.TrimSpace(blocks(strings.Split(src, "\n")))
+}
+
+var (
+	reATX      = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	reHR       = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	reSetext1  = regexp.MustCompile(`^ {0,3}=+[ \t]*$`)
+	reSetext2  = regexp.MustCompile(`^ {0,3}-+[ \t]*$`)
+	reFence    = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`\\s]*)")
+	reQuote    = regexp.MustCompile(`^ {0,3}> ?`)
+	reItem     = regexp.MustCompile(`^( {0,3})([-*+]|\d{1,9}[.)])( +|$)`)
+	reHTMLOpen = regexp.MustCompile(`(?i)^ {0,3}</?(address|article|aside|blockquote|details|div|dl|fieldset|figcaption|figure|footer|form|h[1-6]|header|hr|iframe|ol|p|pre|section|table|ul|video|audio|script|style)[\s/>]`)
+	reComment  = regexp.MustCompile(`^ {0,3}<!--`)
+)
+
+// blocks renders a list of lines as HTML blocks.
+func blocks(lines []string) string {
+	var (
+		b    strings.Builder
+		para []string
+	)
+	flush := func() {
+		if len(para) > 0 {
+			b.WriteString("<p>" + inline(strings.Join(para, "\n")) + "</p>\n")
+			para = nil
+		}
+	}
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+
+		case len(para) > 0 && reSetext1.MatchString(line):
+			b.WriteString("<h1>" + inline(strings.Join(para, "\n")) + "</h1>\n")
+			para = nil
+		case len(para) > 0 && reSetext2.MatchString(line):
+			b.WriteString("<h2>" + inline(strings.Join(para, "\n")) + "</h2>\n")
+			para = nil
+
+		case reHR.MatchString(line):
+			flush()
+			b.WriteString("<hr>\n")
+
+		case reATX.MatchString(line):
+			flush()
+			m := reATX.FindStringSubmatch(line)
+			n := len(m[1])
+			b.WriteString(fmt.Sprintf("<h%d>%s</h%d>\n", n, inline(strings.TrimSpace(m[2])), n))
+
+		case reFence.MatchString(line):
+			flush()
+			m := reFence.FindStringSubmatch(line)
+			indent, fence, lang := len(m[1]), m[2], m[3]
+			code := []string{}
+			for i++; i < len(lines); i++ {
+				if t := strings.TrimSpace(lines[i]); strings.HasPrefix(t, fence) && strings.Trim(t, fence[:1]) == "" {
+					break
+				}
+				code = append(code, trimIndent(lines[i], indent))
+			}
+			class := ""
+			if lang != "" {
+				class = ` class="language-` + html.EscapeString(lang) + `"`
+			}
+			b.WriteString("<pre><code" + class + ">" + html.EscapeString(strings.Join(code, "\n")))
+			if len(code) > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString("</code></pre>\n")
+
+		case len(para) == 0 && strings.HasPrefix(line, "    "):
+			code := []string{}
+			for ; i < len(lines); i++ {
+				if strings.HasPrefix(lines[i], "    ") {
+					code = append(code, lines[i][4:])
+				} else if strings.TrimSpace(lines[i]) == "" {
+					code = append(code, "")
+				} else {
+					break
+				}
+			}
+			i--
+			for len(code) > 0 && code[len(code)-1] == "" {
+				code = code[:len(code)-1]
+			}
+			b.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "\n</code></pre>\n")
+
+		case reQuote.MatchString(line):
+			flush()
+			quoted := []string{}
+			for ; i < len(lines); i++ {
+				if reQuote.MatchString(lines[i]) {
+					quoted = append(quoted, reQuote.ReplaceAllString(lines[i], ""))
+				} else if strings.TrimSpace(lines[i]) != "" && len(quoted) > 0 && strings.TrimSpace(quoted[len(quoted)-1]) != "" {
+					quoted = append(quoted, lines[i]) // Lazy continuation
+				} else {
+					break
+				}
+			}
+			i--
+			b.WriteString("<blockquote>\n" + blocks(quoted) + "</blockquote>\n")
+
+		case reItem.MatchString(line) && (len(para) == 0 || !isOrdered(line)):
+			flush()
+			i = list(lines, i, &b) - 1
+
+		case (reHTMLOpen.MatchString(line) || reComment.MatchString(line)) && len(para) == 0:
+			for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+				b.WriteString(lines[i] + "\n")
+			}
+
+		default:
+			para = append(para, strings.TrimLeft(line, " "))
+		}
+	}
+	flush()
+	return b.String()
+}
+
+// list renders the list starting at lines[i], returning the index of the line following it.
+func list(lines []string, i int, b *strings.Builder) int {
+	m := reItem.FindStringSubmatch(lines[i])
+	ordered := isOrdered(lines[i])
+	marker := m[2][len(m[2])-1:]
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+	}
+	start := ""
+	if n := strings.TrimRight(m[2], ".)"); ordered && n != "1" {
+		start = ` start="` + strings.TrimLeft(n, "0") + `"`
+	}
+	var (
+		items [][]string
+		loose bool
+	)
+	indent := 0
+	for i < len(lines) {
+		line := lines[i]
+		if mm := reItem.FindStringSubmatch(line); mm != nil && !reHR.MatchString(line) && (len(items) == 0 || len(mm[1]) < indent) {
+			if isOrdered(line) != ordered || mm[2][len(mm[2])-1:] != marker {
+				break
+			}
+			indent = len(mm[0])
+			if mm[3] == "" || len(mm[3]) > 4 {
+				indent = len(mm[1]) + len(mm[2]) + 1
+			}
+			items = append(items, []string{trimIndent(line[len(mm[1])+len(mm[2]):], indent-len(mm[1])-len(mm[2]))})
+			i++
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			// A blank line continues the list only if followed by content thereof.
+			j := i + 1
+			for j < len(lines) && strings.TrimSpace(lines[j]) == "" {
+				j++
+			}
+			if j == len(lines) {
+				break
+			}
+			next := lines[j]
+			if indentOf(next) >= indent || (reItem.MatchString(next) && indentOf(next) < indent && isOrdered(next) == ordered) {
+				loose = true
+				items[len(items)-1] = append(items[len(items)-1], "")
+				i++
+				continue
+			}
+			break
+		}
+		if indentOf(line) >= indent {
+			items[len(items)-1] = append(items[len(items)-1], trimIndent(line, indent))
+			i++
+			continue
+		}
+		// Lazy continuation of a paragraph
+		last := items[len(items)-1]
+		if strings.TrimSpace(last[len(last)-1]) != "" && !reItem.MatchString(line) && !reHR.MatchString(line) &&
+			!reATX.MatchString(line) && !reQuote.MatchString(line) && !reFence.MatchString(line) {
+			items[len(items)-1] = append(items[len(items)-1], strings.TrimLeft(line, " "))
+			i++
+			continue
+		}
+		break
+	}
+	b.WriteString("<" + tag + start + ">\n")
+	for _, item := range items {
+		for len(item) > 0 && strings.TrimSpace(item[len(item)-1]) == "" {
+			item = item[:len(item)-1]
+		}
+		s := strings.TrimSpace(blocks(item))
+		if !loose {
+			s = tighten(s)
+		}
+		b.WriteString("<li>" + s + "</li>\n")
+	}
+	b.WriteString("</" + tag + ">\n")
+	return i
+}
+
+// tighten unwraps the paragraphs of a tight list item.
+func tighten(s string) string {
+	s = strings.ReplaceAll(s, "<p>", "")
+	return strings.ReplaceAll(s, "</p>", "")
+}
+
+func isOrdered(line string) bool {
+	m := reItem.FindStringSubmatch(line)
+	return m != nil && m[2][0] >= '0' && m[2][0] <= '9'
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// trimIndent removes up to n leading spaces of a line.
+func trimIndent(line string, n int) string {
+	for i := 0; i < n && strings.HasPrefix(line, " "); i++ {
+		line = line[1:]
+	}
+	return line
+}
+
+// ----------------------------------------------------------------------------
+// Inline
+
+var (
+	reTag      = regexp.MustCompile(`</?[A-Za-z][A-Za-z0-9-]*(?:\s+[A-Za-z_:][\w.:-]*(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s"'=<>` + "`" + `]+))?)*\s*/?>|<!--[\s\S]*?-->`)
+	reAutolink = regexp.MustCompile(`<((?:https?|mailto):[^\s<>]+)>`)
+	reImage    = regexp.MustCompile(`!\[([^\]]*)\]\(\s*<?([^\s)>]*)>?(?:\s+(?:&#34;(.*?)&#34;|&#39;(.*?)&#39;))?\s*\)`)
+	reLink     = regexp.MustCompile(`\[((?:[^\[\]]|\[[^\]]*\])*)\]\(\s*<?([^\s)>]*)>?(?:\s+(?:&#34;(.*?)&#34;|&#39;(.*?)&#39;))?\s*\)`)
+	reStrong   = regexp.MustCompile(`\*\*([^\s*](?:[\s\S]*?[^\s*])?)\*\*|\b__([^\s_](?:[\s\S]*?[^\s_])?)__\b`)
+	reEm       = regexp.MustCompile(`\*([^\s*](?:[^*]*?[^\s*])?)\*|\b_([^\s_](?:[^_]*?[^\s_])?)_\b`)
+	reStrike   = regexp.MustCompile(`~~([^\s~](?:[\s\S]*?[^\s~])?)~~`)
+	reBreak    = regexp.MustCompile(`(?: {2,}|\\)\n`)
+	reEscape   = regexp.MustCompile("\\\\([!\"#$%&'()*+,\\-./:;<=>?@\\[\\]\\\\^_`{|}~])")
+)
+
+// inline renders the inline Markdown of a block as HTML.
+// Code spans, raw HTML tags and backslash escapes are held aside (as placeholders) while the rest is rendered.
+func inline(s string) string {
+	held := []string{}
+	hold := func(v string) string {
+		held = append(held, v)
+		return fmt.Sprintf("\x00%d\x00", len(held)-1)
+	}
+	s = codeSpans(s, hold)
+	s = reEscape.ReplaceAllStringFunc(s, func(m string) string {
+		return hold(html.EscapeString(m[1:]))
+	})
+	s = reAutolink.ReplaceAllStringFunc(s, func(m string) string {
+		u := m[1 : len(m)-1]
+		return hold(`<a href="` + html.EscapeString(u) + `">` + html.EscapeString(strings.TrimPrefix(u, "mailto:")) + `</a>`)
+	})
+	s = reTag.ReplaceAllStringFunc(s, hold)
+
+	s = html.EscapeString(s)
+
+	s = reImage.ReplaceAllStringFunc(s, func(m string) string {
+		mm := reImage.FindStringSubmatch(m)
+		return hold(`<img src="` + mm[2] + `" alt="` + mm[1] + `"` + title(mm[3], mm[4]) + `>`)
+	})
+	s = reLink.ReplaceAllStringFunc(s, func(m string) string {
+		mm := reLink.FindStringSubmatch(m)
+		return `<a href="` + mm[2] + `"` + title(mm[3], mm[4]) + `>` + mm[1] + `</a>`
+	})
+	s = reStrong.ReplaceAllString(s, "<strong>$1$2</strong>")
+	s = reEm.ReplaceAllString(s, "<em>$1$2</em>")
+	s = reStrike.ReplaceAllString(s, "<del>$1</del>")
+	s = reBreak.ReplaceAllString(s, "<br>\n")
+
+	// Restore those held, innermost (latest) first, as those may nest.
+	for i := len(held) - 1; i >= 0; i-- {
+		s = strings.ReplaceAll(s, fmt.Sprintf("\x00%d\x00", i), held[i])
+	}
+	return s
+}
+
+func title(dq, sq string) string {
+	if t := dq + sq; t != "" {
+		return ` title="` + t + `"`
+	}
+	return ""
+}
+
+// codeSpans replaces each code span of s, delimited by backtick runs of equal length, per hold.
+func codeSpans(s string, hold func(string) string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "`")
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		n := i
+		for n < len(s) && s[n] == '`' {
+			n++
+		}
+		run := n - i
+		// Find the closing run of the same length.
+		end, j := -1, n
+		for j < len(s) {
+			k := strings.Index(s[j:], "`")
+			if k < 0 {
+				break
+			}
+			k += j
+			m := k
+			for m < len(s) && s[m] == '`' {
+				m++
+			}
+			if m-k == run {
+				end = k
+				break
+			}
+			j = m
+		}
+		if end < 0 {
+			b.WriteString(s[:n])
+			s = s[n:]
+			continue
+		}
+		code := strings.ReplaceAll(s[n:end], "\n", " ")
+		if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+			code = code[1 : len(code)-1]
+		}
+		b.WriteString(s[:i])
+		b.WriteString(hold("<code>" + html.EscapeString(code) + "</code>"))
+		s = s[end+run:]
+	}
+}
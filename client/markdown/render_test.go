@@ -0,0 +1,37 @@
+package markdown
+
+import (
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{"atx", "# Title #\n\n###### Six", "<h1>Title</h1>\n<h6>Six</h6>"},
+		{"setext", "Title\n=====\n\nSub\n---", "<h1>Title</h1>\n<h2>Sub</h2>"},
+		{"inline", "Some *em* and **strong** and `co<de>` and ~~gone~~.",
+			"<p>Some <em>em</em> and <strong>strong</strong> and <code>co&lt;de&gt;</code> and <del>gone</del>.</p>"},
+		{"escapes", `a \*not em\* & <b>tag</b>`, "<p>a *not em* &amp; <b>tag</b></p>"},
+		{"links", `[link](https://x.com "T") ![alt](/a.jpg) <https://x.com/auto>`,
+			`<p><a href="https://x.com" title="T">link</a> <img src="/a.jpg" alt="alt"> <a href="https://x.com/auto">https://x.com/auto</a></p>`},
+		{"reference links unsupported", "[ref][1]\n\n[1]: https://x.com", "<p>[ref][1]</p>\n<p>[1]: https://x.com</p>"},
+		{"hard break", "line one\nline two  \nline three", "<p>line one\nline two<br>\nline three</p>"},
+		{"crlf and tabs", "a\r\nb\r\n\r\n\tcode", "<p>a\nb</p>\n<pre><code>code\n</code></pre>"},
+		{"nested list", "- a\n- b\n    - c\n- d", "<ul>\n<li>a</li>\n<li>b\n<ul>\n<li>c</li>\n</ul></li>\n<li>d</li>\n</ul>"},
+		{"ordered list", "3) x\n4) y", "<ol start=\"3\">\n<li>x</li>\n<li>y</li>\n</ol>"},
+		{"loose list", "- a\n\n- b", "<ul>\n<li><p>a</p></li>\n<li><p>b</p></li>\n</ul>"},
+		{"blockquote", "> quoted\nlazy\n\n> - item",
+			"<blockquote>\n<p>quoted\nlazy</p>\n</blockquote>\n<blockquote>\n<ul>\n<li>item</li>\n</ul>\n</blockquote>"},
+		{"fenced code", "```go\nfmt.Println(\"<x>\")\n```", "<pre><code class=\"language-go\">fmt.Println(&#34;&lt;x&gt;&#34;)\n</code></pre>"},
+		{"indented code", "    indented\n    code\n\nafter", "<pre><code>indented\ncode\n</code></pre>\n<p>after</p>"},
+		{"thematic break", "a\n\n***\n\nb", "<p>a</p>\n<hr>\n<p>b</p>"},
+		{"html block", "<div class=\"x\">\n*raw*\n</div>\n\npara", "<div class=\"x\">\n*raw*\n</div>\n<p>para</p>"},
+		{"empty", "\n\n", ""},
+	}
+	for _, tt := range tests {
+		if got := Render(tt.src); got != tt.want {
+			t.Errorf("%s : Render(%q)\n got: %q\nwant: %q", tt.name, tt.src, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package mirror
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PathSecrets is the folder of Docker secrets.
+const PathSecrets = "/run/secrets"
+
+// nonAlphaNum matches those characters of a credentials reference invalid in an environment variable name.
+var nonAlphaNum = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// Credentials returns the WordPress credentials (user, pass) of a site per its reference (Site.Auth);
+// an application password (WordPress 5.6+) for Basic Auth, stored as "<user>:<pass>" (see Secret).
+// Credentials are never written to the sites list, which holds only their reference.
+func Credentials(ns, ref string) (user, pass string, err error) {
+	val, err := Secret(ns, ref)
+	if err != nil {
+		return "", "", err
+	}
+	ss := strings.SplitN(val, ":", 2)
+	if len(ss) != 2 || ss[0] == "" || ss[1] == "" {
+		return "", "", errors.Errorf("malformed credentials : %s", ref)
+	}
+	return ss[0], ss[1], nil
+}
+
+// Secret returns the secret of a site per its reference (Site.Auth), as stored
+// at its Docker secret (/run/secrets/<ref>), else at its environment variable (<NS>_WP_AUTH_<REF>).
+func Secret(ns, ref string) (string, error) {
+	if ref == "" {
+		return "", errors.New("missing credentials reference")
+	}
+	val := ""
+	if bb, err := os.ReadFile(filepath.Join(PathSecrets, filepath.Base(ref))); err == nil {
+		val = string(bb)
+	}
+	if val == "" {
+		val = os.Getenv(CredentialsEnvVar(ns, ref))
+	}
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return "", errors.Errorf("credentials NOT FOUND : %s", ref)
+	}
+	return val, nil
+}
+
+// CredentialsEnvVar returns the name of the environment variable of credentials per reference (ref), e.g.,
+//
+//	ns: "APP", ref: "the-wp-site" => "APP_WP_AUTH_THE_WP_SITE"
+func CredentialsEnvVar(ns, ref string) string {
+	return ns + "_WP_AUTH_" + strings.Trim(nonAlphaNum.ReplaceAllString(strings.ToUpper(ref), "_"), "_")
+}
@@ -0,0 +1,250 @@
+package mirror
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Rule is a transform of the HTML (node tree) of a message body, applied in place.
+// Links relative to the site are resolved against its base URL.
+type Rule func(root *html.Node, base *url.URL)
+
+// Rules is the registry of named rules available to a Cleaner chain (Site.Cleaners).
+var Rules = map[string]Rule{
+	"scripts":  stripScripts,
+	"styles":   stripStyles,
+	"pixels":   stripPixels,
+	"widgets":  stripWidgets,
+	"absolute": absolutize,
+	"empty":    stripEmpty,
+}
+
+// CleanersNone declared as a site's sole cleaner disables cleaning of its messages.
+const CleanersNone = "none"
+
+// DefaultCleaners is the chain of rules applied to sites declaring none; none, cleaning being opt-in per site.
+var DefaultCleaners = []string{}
+
+// WidgetClasses are those (CSS) classes of share, related-posts and ad blocks
+// injected into post content by popular WordPress plugins and themes.
+var WidgetClasses = []string{
+	"sharedaddy", "sd-sharing", "sd-block", "jp-relatedposts", "wpcnt", "wordads",
+	"addtoany_share_save_container", "a2a_kit", "heateor_sss_sharing_container",
+	"social-share", "share-buttons", "sharethis-inline-share-buttons",
+	"adsbygoogle", "advertisement", "ad-container",
+	"yarpp-related", "crp_related", "mailmunch-forms-before-post",
+}
+
+// NewCleaner returns that which applies the named rules (names), in order, to an HTML fragment.
+// The default chain applies if no names, and the fragment is returned as is per CleanersNone, else of no rules.
+func NewCleaner(hostURL string, names ...string) (func(string) string, error) {
+	if len(names) == 0 {
+		names = DefaultCleaners
+	}
+	if len(names) == 0 || (len(names) == 1 && names[0] == CleanersNone) {
+		return func(s string) string { return s }, nil
+	}
+	chain := []Rule{}
+	for _, name := range names {
+		rule, ok := Rules[name]
+		if !ok {
+			return nil, errors.Errorf("unknown cleaner : %s", name)
+		}
+		chain = append(chain, rule)
+	}
+	base, err := url.Parse(hostURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing host url")
+	}
+	return func(s string) string {
+		if strings.TrimSpace(s) == "" {
+			return s
+		}
+		root := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+		nodes, err := html.ParseFragment(strings.NewReader(s), root)
+		if err != nil {
+			return s
+		}
+		for _, n := range nodes {
+			root.AppendChild(n)
+		}
+		for _, rule := range chain {
+			rule(root, base)
+		}
+		var buf bytes.Buffer
+		for n := root.FirstChild; n != nil; n = n.NextSibling {
+			if err := html.Render(&buf, n); err != nil {
+				return s
+			}
+		}
+		return strings.TrimSpace(buf.String())
+	}, nil
+}
+
+// Walk calls fn on n and each of its descendants, depth first, descending only into those for which fn returns true.
+func Walk(n *html.Node, fn func(*html.Node) bool) {
+	if !fn(n) {
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		Walk(c, fn)
+	}
+}
+
+// prune calls fn on each descendant element of n, depth first, removing those for which fn returns true.
+func prune(n *html.Node, fn func(*html.Node) bool) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.ElementNode && fn(c) {
+			n.RemoveChild(c)
+		} else {
+			prune(c, fn)
+		}
+		c = next
+	}
+}
+
+// Attr returns the value of the named attribute of n.
+func Attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// HasClass reports whether n has any of the (CSS) classes.
+func HasClass(n *html.Node, classes ...string) bool {
+	for _, c := range strings.Fields(Attr(n, "class")) {
+		for _, want := range classes {
+			if c == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stripScripts removes scripts and inline event handlers.
+func stripScripts(root *html.Node, _ *url.URL) {
+	prune(root, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.Script, atom.Noscript:
+			return true
+		}
+		attrs := n.Attr[:0]
+		for _, a := range n.Attr {
+			if !strings.HasPrefix(a.Key, "on") {
+				attrs = append(attrs, a)
+			}
+		}
+		n.Attr = attrs
+		return false
+	})
+}
+
+// stripStyles removes stylesheets and inline styles.
+func stripStyles(root *html.Node, _ *url.URL) {
+	prune(root, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.Style:
+			return true
+		case atom.Link:
+			return strings.Contains(Attr(n, "rel"), "stylesheet")
+		}
+		attrs := n.Attr[:0]
+		for _, a := range n.Attr {
+			if a.Key != "style" {
+				attrs = append(attrs, a)
+			}
+		}
+		n.Attr = attrs
+		return false
+	})
+}
+
+// stripPixels removes tracking pixels; images of 1x1 (or 0) size, and those of known trackers.
+func stripPixels(root *html.Node, _ *url.URL) {
+	trackers := []string{"pixel.wp.com", "stats.wp.com", "feeds.feedburner.com/~r", "/piwik.php", "/matomo.php"}
+	prune(root, func(n *html.Node) bool {
+		if n.DataAtom != atom.Img {
+			return false
+		}
+		w, h := Attr(n, "width"), Attr(n, "height")
+		if (w == "1" || w == "0") && (h == "1" || h == "0") {
+			return true
+		}
+		src := Attr(n, "src")
+		for _, t := range trackers {
+			if strings.Contains(src, t) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// stripWidgets removes share, related-posts and ad blocks per WidgetClasses.
+func stripWidgets(root *html.Node, _ *url.URL) {
+	prune(root, func(n *html.Node) bool {
+		return HasClass(n, WidgetClasses...) || n.DataAtom == atom.Ins
+	})
+}
+
+// absolutize resolves relative URLs of links and media against the site's base URL.
+func absolutize(root *html.Node, base *url.URL) {
+	if base == nil || base.Host == "" {
+		return
+	}
+	prune(root, func(n *html.Node) bool {
+		for i, a := range n.Attr {
+			switch a.Key {
+			case "href", "src", "poster":
+			default:
+				continue
+			}
+			if strings.HasPrefix(a.Val, "#") || strings.HasPrefix(a.Val, "data:") {
+				continue
+			}
+			if u, err := url.Parse(strings.TrimSpace(a.Val)); err == nil && !u.IsAbs() {
+				n.Attr[i].Val = base.ResolveReference(u).String()
+			}
+		}
+		return false
+	})
+}
+
+// stripEmpty removes paragraphs containing neither text (but whitespace) nor media.
+func stripEmpty(root *html.Node, _ *url.URL) {
+	var empty func(n *html.Node) bool
+	empty = func(n *html.Node) bool {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch c.Type {
+			case html.TextNode:
+				if strings.TrimSpace(strings.ReplaceAll(c.Data, "\u00a0", " ")) != "" {
+					return false
+				}
+			case html.ElementNode:
+				switch c.DataAtom {
+				case atom.Br:
+				case atom.Img, atom.Iframe, atom.Video, atom.Audio, atom.Object, atom.Embed, atom.Svg:
+					return false
+				default:
+					if !empty(c) {
+						return false
+					}
+				}
+			}
+		}
+		return true
+	}
+	prune(root, func(n *html.Node) bool {
+		return n.DataAtom == atom.P && empty(n)
+	})
+}
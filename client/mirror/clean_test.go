@@ -0,0 +1,58 @@
+package mirror
+
+import (
+	"testing"
+)
+
+func TestCleaner(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		in    string
+		want  string
+	}{
+		{"scripts", []string{"scripts"},
+			`<p onclick="x()">a</p><script>alert(1)</script><noscript>b</noscript>`,
+			`<p>a</p>`},
+		{"styles", []string{"styles"},
+			`<style>p{}</style><link rel="stylesheet" href="/a.css"/><p style="color:red">a</p>`,
+			`<p>a</p>`},
+		{"pixels", []string{"pixels"},
+			`<p>a<img src="/x.gif" width="1" height="1"/><img src="https://pixel.wp.com/g.gif"/><img src="/a.jpg"/></p>`,
+			`<p>a<img src="/a.jpg"/></p>`},
+		{"widgets", []string{"widgets"},
+			`<p>a</p><div class="sharedaddy sd-sharing">share</div><ins class="adsbygoogle"></ins>`,
+			`<p>a</p>`},
+		{"absolute", []string{"absolute"},
+			`<a href="/a?b=1">a</a><img src="img/x.jpg"/><a href="#top">t</a><a href="https://y.com/">y</a>`,
+			`<a href="https://x.com/a?b=1">a</a><img src="https://x.com/img/x.jpg"/><a href="#top">t</a><a href="https://y.com/">y</a>`},
+		{"empty", []string{"empty"},
+			"<p>a</p><p> &nbsp; <br/></p><p><img src=\"/a.jpg\"/></p>",
+			`<p>a</p><p><img src="/a.jpg"/></p>`},
+		{"none", []string{CleanersNone},
+			`<p style="x">a</p><script>s</script>`,
+			`<p style="x">a</p><script>s</script>`},
+		{"widgets keep code", []string{"widgets"},
+			`<div class="code-block"><pre>x</pre></div>`,
+			`<div class="code-block"><pre>x</pre></div>`},
+		{"default", nil,
+			`<p style="x"><a href="/a">a</a></p><p></p><script>s</script>`,
+			`<p style="x"><a href="/a">a</a></p><p></p><script>s</script>`},
+		{"chain", []string{"scripts", "styles", "pixels", "widgets", "absolute", "empty"},
+			`<p style="x"><a href="/a">a</a></p><p></p><script>s</script>`,
+			`<p><a href="https://x.com/a">a</a></p>`},
+		{"blank", nil, "  ", "  "},
+	}
+	for _, tt := range tests {
+		clean, err := NewCleaner("https://x.com/", tt.names...)
+		if err != nil {
+			t.Fatalf("%s : %s", tt.name, err)
+		}
+		if got := clean(tt.in); got != tt.want {
+			t.Errorf("%s\n got: %s\nwant: %s", tt.name, got, tt.want)
+		}
+	}
+	if _, err := NewCleaner("https://x.com/", "bogus"); err == nil {
+		t.Errorf("unknown cleaner : got nil error")
+	}
+}
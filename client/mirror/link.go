@@ -0,0 +1,118 @@
+package mirror
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TrackingParams are those query parameters of a link insignificant to the resource it locates;
+// those of campaigns (utm_*) and click ids.
+var TrackingParams = []string{
+	"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content",
+	"fbclid", "gclid", "msclkid", "mc_cid", "mc_eid",
+}
+
+// AMPParam is the query parameter declaring a link that of the AMP variant of its resource, e.g., ?amp or ?amp=1.
+const AMPParam = "amp"
+
+// slashes matches runs of (path) slashes.
+var slashes = regexp.MustCompile(`/{2,}`)
+
+// LinkToURI derives the (canonical) URI of a post from its link, relative to the site (hostURL), e.g.,
+//
+//	"https://foo.bar.baz/a/b"              => "/a/b"
+//	"https://www.foo.bar.baz/a/b/amp/?amp" => "/a/b/"
+//	"https://foo.bar.baz?p=12&utm_source=x" => "/?p=12"
+//	"https://cdn.other.com/a/b"            => "https://cdn.other.com/a/b"
+//
+// Links of another host retain it, else posts thereof would be indistinguishable.
+// Significant query parameters are retained (sorted), and the fragment.
+// The trailing /amp of the path is that of an AMP variant only per AMPParam, else it is of the resource (/tags/amp/).
+// Trailing slashes are retained as WordPress renders them, lest Message.ID (per URI) change.
+func LinkToURI(link, hostURL string) (string, error) {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return "", errors.New("missing link")
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", errors.Wrap(err, "parsing link")
+	}
+	site, _ := url.Parse(hostURL)
+	if u.Host == "" {
+		if site == nil || site.Host == "" {
+			return "", errors.Errorf("link sans host : %s", link)
+		}
+		if u.Scheme != "" {
+			return "", errors.Errorf("malformed link : %s", link)
+		}
+		u = site.ResolveReference(u)
+	}
+
+	// Query
+	q := u.Query()
+	for _, k := range TrackingParams {
+		q.Del(k)
+	}
+	amp := q.Has(AMPParam) && (q.Get(AMPParam) == "" || q.Get(AMPParam) == "1")
+	if amp {
+		q.Del(AMPParam)
+	}
+
+	// Path
+	p := slashes.ReplaceAllString(u.EscapedPath(), "/")
+	if amp && (strings.HasSuffix(p, "/amp/") || strings.HasSuffix(p, "/amp")) {
+		p = strings.TrimSuffix(strings.TrimSuffix(p, "/"), "/amp") + "/"
+	}
+	if p == "" {
+		p = "/"
+	}
+	uri := p
+	if len(q) > 0 {
+		uri += "?" + q.Encode()
+	}
+	if u.Fragment != "" {
+		uri += "#" + u.EscapedFragment()
+	}
+
+	// Host
+	host := canonicalHost(u)
+	if site != nil && site.Host != "" && host != canonicalHost(site) {
+		scheme := strings.ToLower(u.Scheme)
+		if scheme == "" {
+			scheme = "https"
+		}
+		return scheme + "://" + strings.ToLower(u.Hostname()) + uri, nil
+	}
+	return uri, nil
+}
+
+// Host returns the host (name) of a URL, lowercased sans "www."; empty if none.
+func Host(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// canonicalHost returns that of a URL (see Host) sans "amp." prefix.
+func canonicalHost(u *url.URL) string {
+	return strings.TrimPrefix(Host(u.String()), "amp.")
+}
+
+// Resolve returns a reference (ref) resolved against its base URL; ref as is if either is malformed.
+func Resolve(base, ref string) string {
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
@@ -0,0 +1,80 @@
+package mirror
+
+import (
+	"testing"
+)
+
+func TestLinkToURI(t *testing.T) {
+	const host = "https://foo.bar.baz"
+	tests := []struct {
+		link, host, want string
+		err              bool
+	}{
+		{"https://foo.bar.baz/a/b", host, "/a/b", false},
+		{"https://foo.bar.baz/a/b/", host, "/a/b/", false},
+		{"https://www.foo.bar.baz/a/b/amp/?amp", host, "/a/b/", false},
+		{"https://foo.bar.baz/a/b/amp?amp=1&x=2", host, "/a/b/?x=2", false},
+		{"https://foo.bar.baz/?amp=1", host, "/", false},
+		{"https://foo.bar.baz/tags/amp/", host, "/tags/amp/", false},
+		{"https://foo.bar.baz/guitar/amp", host, "/guitar/amp", false},
+		{"https://foo.bar.baz/guitar/amp?amp=tube", host, "/guitar/amp?amp=tube", false},
+		{"https://foo.bar.baz/a?ref=x&share=y&utm_medium=z&msclkid=1", host, "/a?ref=x&share=y", false},
+		{"https://FOO.bar.baz/a//b", host, "/a/b", false},
+		{"https://foo.bar.baz?p=12&utm_source=x", host, "/?p=12", false},
+		{"https://foo.bar.baz/a?z=1&a=2&fbclid=x#c", host, "/a?a=2&z=1#c", false},
+		{"https://foo.bar.baz/caf%C3%A9/", host, "/caf%C3%A9/", false},
+		{"/a/b", host, "/a/b", false},
+		{"https://cdn.other.com/a/b", host, "https://cdn.other.com/a/b", false},
+		{"https://foo.bar.baz/a/b", "", "/a/b", false},
+		{"", host, "", true},
+		{"/a/b", "", "", true},
+		{"http://[::1", host, "", true},
+	}
+	for _, tt := range tests {
+		got, err := LinkToURI(tt.link, tt.host)
+		if (err != nil) != tt.err {
+			t.Errorf("LinkToURI(%q, %q) err = %v, want err %v", tt.link, tt.host, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("LinkToURI(%q, %q) = %q, want %q", tt.link, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		base, ref, want string
+	}{
+		{"https://x.com/feed/", "page/2", "https://x.com/feed/page/2"},
+		{"https://x.com/feed/", "/a.jpg", "https://x.com/a.jpg"},
+		{"https://x.com/feed", "?page=2", "https://x.com/feed?page=2"},
+		{"https://x.com/feed", "https://cdn.y.com/a.jpg", "https://cdn.y.com/a.jpg"},
+		{"https://x.com/", "", "https://x.com/"},
+		{"http://[::1", "/a", "/a"},
+		{"https://x.com/", "http://[::1", "http://[::1"},
+	}
+	for _, tt := range tests {
+		if got := Resolve(tt.base, tt.ref); got != tt.want {
+			t.Errorf("Resolve(%q, %q) = %q, want %q", tt.base, tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestHost(t *testing.T) {
+	tests := []struct {
+		url, want string
+	}{
+		{"https://www.Foo.Bar/a", "foo.bar"},
+		{"http://amp.foo.bar:8080", "amp.foo.bar"},
+		{"//WWW.foo.bar", "foo.bar"},
+		{"/a/b", ""},
+		{"", ""},
+		{"http://[::1", ""},
+	}
+	for _, tt := range tests {
+		if got := Host(tt.url); got != tt.want {
+			t.Errorf("Host(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
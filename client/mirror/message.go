@@ -0,0 +1,79 @@
+package mirror
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sempernow/kit/id"
+	"github.com/sempernow/kit/types/str"
+)
+
+// AuthorTag appends the name of an author to a list of tags, per the rules common to all sources.
+func AuthorTag(tags []string, author string) []string {
+	if author != "" && !strings.Contains(author, "s") {
+		tags = append(tags, author)
+	}
+	return tags
+}
+
+// MessageID is the deterministic id of a message of a channel: UUIDv5(chnID, name),
+// where name is that of its identity; its URI by default. Empty on fail.
+func MessageID(chnID, name string) string {
+	u, err := id.UUIDv5(chnID, name)
+	if err != nil {
+		return ""
+	}
+	return u
+}
+
+// DateUpdate recovers the timestamp of a message: the first non-zero of tt,
+// else the current time (UTC).
+func DateUpdate(tt ...time.Time) time.Time {
+	for _, t := range tt {
+		if !IsZero(t) {
+			return t
+		}
+	}
+	log.Printf("WARN : msg.DateUpdate : NOT FOUND : Set to current time.\n")
+	return time.Now().Truncate(1 * time.Second).UTC()
+}
+
+// Sanitize each name of list.
+func Sanitize(names []string) {
+	for i, name := range names {
+		names[i] = str.CleanAlphaNum(name, 35)
+	}
+}
+
+// Figure renders an image (src) as HTML to prepend to a message body; dimensions are optional (zero).
+func Figure(src, alt string, width, height int) string {
+	dims := ""
+	if width > 0 && height > 0 {
+		dims = fmt.Sprintf(` width="%d" height="%d"`, width, height)
+	}
+	return fmt.Sprintf(`<figure class="wp-featured-image"><img src="%s" alt="%s"%s></figure>`,
+		html.EscapeString(src),
+		html.EscapeString(strings.TrimSpace(alt)),
+		dims,
+	)
+}
+
+// Renders reports whether a message body already renders an image (src), at any size thereof.
+func Renders(body, src string) bool {
+	return strings.Contains(body, strings.TrimSuffix(src, path.Ext(src)))
+}
+
+// IsZero tests for either the zero time of Unix or that of time pkg.
+func IsZero(t time.Time) bool {
+	return IsUnixZero(t) || t.IsZero()
+}
+
+// IsUnixZero tests for "1970-01-01 00:00:00 +0000 UTC".
+// Unlike time pkg t.IsZero(), which tests for "0001-01-01 00:00:00 +0000 UTC".
+func IsUnixZero(t time.Time) bool {
+	return t == time.Unix(0, 0).UTC()
+}
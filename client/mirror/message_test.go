@@ -0,0 +1,37 @@
+package mirror
+
+import (
+	"testing"
+)
+
+const testChnID = "d5750f33-a12d-4719-9600-94fcee80f487"
+
+func TestMessageID(t *testing.T) {
+	a := MessageID(testChnID, "/a/b")
+	if a == "" || a != MessageID(testChnID, "/a/b") {
+		t.Fatalf("MessageID not stable : %q", a)
+	}
+	if a == MessageID(testChnID, "/a/c") {
+		t.Errorf("MessageID of distinct names equal")
+	}
+}
+
+func TestItemID(t *testing.T) {
+	const uri = "/a-post/"
+	tests := []struct {
+		identity, id, guid, name string
+	}{
+		{"", "7", "urn:x:7", uri},
+		{IdentityURI, "7", "urn:x:7", uri},
+		{IdentityID, "7", "urn:x:7", "7"},
+		{IdentityID, "", "urn:x:7", uri},
+		{IdentityGUID, "7", "urn:x:7", "urn:x:7"},
+		{IdentityGUID, "7", "", uri},
+	}
+	for _, tt := range tests {
+		a := Adapter{Site: &Site{ChnID: testChnID, Identity: tt.identity}}
+		if got, want := a.ItemID(uri, tt.id, tt.guid), MessageID(testChnID, tt.name); got != want {
+			t.Errorf("%q : ItemID(%q, %q, %q) = %s, want that of %q", tt.identity, uri, tt.id, tt.guid, got, tt.name)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Package mirror contains that common to the source adapters of sites mirrored at Uqrate channels;
+// the site record (Site) and its options, and the rules mapping an item of any source into a Uqrate message
+// (cleaners, summaries, URIs, ids, dates, tags and images).
+package mirror
+
+import (
+	"log"
+
+	"github.com/sempernow/uqc/client"
+)
+
+// Adapter contains the app environment and per-site configuration common to the source adapter of a site.
+type Adapter struct {
+	Env     *client.Env
+	Site    *Site
+	Cleaner func(string) string
+}
+
+// NewAdapter returns the Adapter of a site, whose Cleaner is that per its declared cleaners (Site.Cleaners).
+func NewAdapter(env *client.Env, site *Site) Adapter {
+	cleaner, err := NewCleaner(site.HostURL, site.Cleaners...)
+	if err != nil {
+		log.Printf("ERR : NewCleaner @ %s : %s\n", site.HostURL, err.Error())
+	}
+	return Adapter{Env: env, Site: site, Cleaner: cleaner}
+}
+
+// ItemID returns the id (Message.ID) of the message of an item per identity strategy of its site (Site.Identity);
+// that of the item's id (IdentityID) else guid (IdentityGUID) if it has such, else that of its URI (uri).
+func (a Adapter) ItemID(uri, id, guid string) string {
+	key := uri
+	switch a.Site.Identity {
+	case IdentityID:
+		if id != "" {
+			key = id
+		}
+	case IdentityGUID:
+		if guid != "" {
+			key = guid
+		}
+	}
+	return MessageID(a.Site.ChnID, key)
+}
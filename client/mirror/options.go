@@ -0,0 +1,277 @@
+package mirror
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sempernow/uqc/client"
+)
+
+// ColumnsCSV are the fields of a record of the sites-list CSV file sans header, in order,
+// optionally followed by that of options (see SetOptions).
+var ColumnsCSV = []string{"user_handle", "slug", "host_url", "owner_id", "chn_id"}
+
+// SetOptions sets per-site options declared in the (optional) options field of a sites-list record,
+// as whitespace-delimited key=value pairs; list values are comma delimited. E.g.,
+//
+//	"cleaners=scripts,styles,absolute summary=markdown"
+func (s *Site) SetOptions(opts string) error {
+	for _, kv := range strings.Fields(opts) {
+		ss := strings.SplitN(kv, "=", 2)
+		if len(ss) != 2 {
+			return errors.Errorf("malformed option : %s", kv)
+		}
+		if err := s.SetOption(ss[0], ss[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetOption sets a per-site option (key) to its declared value (val).
+func (s *Site) SetOption(key, val string) error {
+	switch strings.ToLower(strings.TrimSpace(key)) {
+	case "source":
+		s.Source = strings.ToLower(val)
+	case "cleaners":
+		names := list(val)
+		for _, name := range names {
+			if _, ok := Rules[name]; !ok && name != CleanersNone {
+				return errors.Errorf("unknown cleaner : %s", name)
+			}
+		}
+		s.Cleaners = names
+	case "summary":
+		switch val {
+		case SummaryText, SummaryMarkdown, SummaryHTML:
+			s.SummaryFormat = val
+		default:
+			return errors.Errorf("unknown summary format : %s", val)
+		}
+	case "summary_sentences":
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 1 {
+			return errors.Errorf("malformed summary_sentences : %s", val)
+		}
+		s.SummarySentences = n
+	case "types":
+		s.Types = list(val)
+	case "api":
+		switch val {
+		case APIWPJSON, APIWPCOM, APIRestRoute:
+			s.API = val
+		default:
+			return errors.Errorf("unknown api mode : %s", val)
+		}
+	case "api_root":
+		s.APIRoot = val
+	case "auth":
+		s.Auth = val
+	case "comments":
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return errors.Errorf("malformed comments : %s", val)
+		}
+		s.Comments = b
+	case "identity":
+		switch val {
+		case IdentityURI, IdentityID, IdentityGUID:
+			s.Identity = val
+		default:
+			return errors.Errorf("unknown identity : %s", val)
+		}
+	case "feed_url":
+		s.FeedURL = val
+	case "sitemap_url":
+		s.SitemapURL = val
+	case "dir":
+		s.Dir = val
+	case "actor":
+		s.Actor = val
+	case "rate_limit":
+		d, err := time.ParseDuration(val)
+		if err != nil || d < 0 {
+			return errors.Errorf("malformed rate_limit : %s", val)
+		}
+		s.RateLimit = Duration(d)
+	case "tag_policy":
+		switch val {
+		case TagsAll, TagsCats, TagsTags, TagsNone:
+			s.TagPolicy = val
+		default:
+			return errors.Errorf("unknown tag policy : %s", val)
+		}
+	case "enabled":
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return errors.Errorf("malformed enabled : %s", val)
+		}
+		s.Disabled = !b
+	case "schedule":
+		d, err := time.ParseDuration(val)
+		if err != nil || d < 0 {
+			return errors.Errorf("malformed schedule : %s", val)
+		}
+		s.Schedule = Duration(d)
+	default:
+		return errors.Errorf("unknown option : %s", key)
+	}
+	return nil
+}
+
+// SetField sets a field (key) of a sites-list record to its declared value (val);
+// those of identity (handle, slug, host, owner and channel), else the options (see SetOptions),
+// else an option (see SetOption).
+func (s *Site) SetField(key, val string) error {
+	val = strings.TrimSpace(val)
+	switch strings.ToLower(strings.TrimSpace(key)) {
+	case "user_handle", "handle":
+		s.UserHandle = val
+	case "chn_slug", "slug":
+		s.ChnSlug = val
+	case "host_url":
+		s.HostURL = val
+	case "owner_id":
+		s.OwnerID = val
+	case "chn_id":
+		s.ChnID = val
+	case "options":
+		return s.SetOptions(val)
+	default:
+		if val == "" {
+			return nil
+		}
+		return s.SetOption(key, val)
+	}
+	return nil
+}
+
+// Record returns the fields of a site as those of its sites-list record (see SetField), in order;
+// identity (per ColumnsCSV), then its options (see SetOption) of other than default value.
+// Lists are comma delimited.
+func (s Site) Record() [][2]string {
+	kv := [][2]string{
+		{"user_handle", s.UserHandle},
+		{"slug", s.ChnSlug},
+		{"host_url", s.HostURL},
+		{"owner_id", s.OwnerID},
+		{"chn_id", s.ChnID},
+	}
+	opt := func(key, val string) {
+		if val != "" {
+			kv = append(kv, [2]string{key, val})
+		}
+	}
+	opt("source", s.Source)
+	opt("cleaners", strings.Join(s.Cleaners, ","))
+	opt("summary", s.SummaryFormat)
+	if s.SummarySentences > 0 {
+		opt("summary_sentences", strconv.Itoa(s.SummarySentences))
+	}
+	opt("types", strings.Join(s.Types, ","))
+	opt("api", s.API)
+	opt("api_root", s.APIRoot)
+	opt("auth", s.Auth)
+	if s.Comments {
+		opt("comments", "true")
+	}
+	opt("identity", s.Identity)
+	opt("feed_url", s.FeedURL)
+	opt("sitemap_url", s.SitemapURL)
+	opt("dir", s.Dir)
+	opt("actor", s.Actor)
+	if s.RateLimit > 0 {
+		opt("rate_limit", time.Duration(s.RateLimit).String())
+	}
+	opt("tag_policy", s.TagPolicy)
+	if s.Disabled {
+		opt("enabled", "false")
+	}
+	if s.Schedule > 0 {
+		opt("schedule", time.Duration(s.Schedule).String())
+	}
+	return kv
+}
+
+// Options renders the options of a site as those of the options field of its sites-list record; see SetOptions.
+func (s Site) Options() string {
+	ss := []string{}
+	for _, kv := range s.Record()[len(ColumnsCSV):] {
+		ss = append(ss, kv[0]+"="+kv[1])
+	}
+	return strings.Join(ss, " ")
+}
+
+// Tag policies (TagPolicy) of the messages of a site
+const (
+	TagsAll  = "all"  // Categories and tags (default)
+	TagsCats = "cats" // Categories only
+	TagsTags = "tags" // Tags only
+	TagsNone = "none"
+)
+
+// ApplyTagPolicy drops the categories and/or tags of a message per the tag policy of its site.
+func (s Site) ApplyTagPolicy(msg *client.Message) {
+	switch s.TagPolicy {
+	case TagsCats:
+		msg.Tags = nil
+	case TagsTags:
+		msg.Cats = nil
+	case TagsNone:
+		msg.Cats = nil
+		msg.Tags = nil
+	}
+}
+
+// Enabled reports whether the site is processed; see option "enabled".
+func (s Site) Enabled() bool {
+	return !s.Disabled
+}
+
+// DefaultRateLimit is the pause following each request to a site declaring none (RateLimit).
+const DefaultRateLimit = time.Millisecond * 300
+
+// Pause sleeps per the rate limit of the site, following a request thereto.
+func (s Site) Pause() {
+	if s.RateLimit > 0 {
+		time.Sleep(time.Duration(s.RateLimit))
+		return
+	}
+	time.Sleep(DefaultRateLimit)
+}
+
+// Duration is that of an option; (un)marshalled as such, e.g., "90s".
+type Duration time.Duration
+
+// MarshalJSON renders the duration per time.Duration.String.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(time.Duration(d).String())), nil
+}
+
+// UnmarshalJSON parses a duration rendered as such, else as nanoseconds.
+func (d *Duration) UnmarshalJSON(bb []byte) error {
+	s := strings.Trim(string(bb), `"`)
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		*d = Duration(n)
+		return nil
+	}
+	t, err := time.ParseDuration(s)
+	if err != nil {
+		return errors.Wrap(err, "parsing duration")
+	}
+	*d = Duration(t)
+	return nil
+}
+
+// list splits a comma-delimited value into its (trimmed, lowercased, non-empty) elements.
+func list(val string) []string {
+	ss := []string{}
+	for _, s := range strings.Split(val, ",") {
+		if s = strings.ToLower(strings.TrimSpace(s)); s != "" {
+			ss = append(ss, s)
+		}
+	}
+	return ss
+}
@@ -0,0 +1,116 @@
+package mirror
+
+import (
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// API modes (Site.API) : the base of a site's WordPress REST API.
+const (
+	APIWPJSON    = "wpjson"     // <HostURL>/wp-json/... (Default)
+	APIWPCOM     = "wpcom"      // https://public-api.wordpress.com/wp/v2/sites/<domain>/...
+	APIRestRoute = "rest_route" // <HostURL>/?rest_route=/...
+)
+
+// Identity strategies (Site.Identity) : the name, per Message.ChnID namespace,
+// of the static UUID (v5) of an item's message (Message.ID).
+const (
+	IdentityURI  = "uri"  // Message.URI; changes with slug or permalink structure (Default)
+	IdentityID   = "id"   // Item id; that of a WordPress post, else of a feed item (guid, id), ...
+	IdentityGUID = "guid" // Item GUID; that of a WordPress post, else as IdentityID
+)
+
+// Site contains that required to map a site (of any source) to a Uqrate Channel.
+type Site struct {
+	UserHandle string `json:"user_handle,omitempty"`
+	ChnSlug    string `json:"chn_slug,omitempty"`
+	HostURL    string `json:"host_url,omitempty"`
+	OwnerID    string `json:"owner_id,omitempty"`
+	ChnID      string `json:"chn_id,omitempty"`
+	Skipped    []Skip `json:"-"`
+	Row        int    `json:"-"` // Of its record at the sites-list CSV file
+	Error      string `json:"error,omitempty"`
+	Status     `json:"status,omitempty"`
+
+	// Options : per site (see SetOption)
+	Source           string   `json:"source,omitempty"` // wordpress (default), ...; see package source
+	Cleaners         []string `json:"cleaners,omitempty"`
+	SummaryFormat    string   `json:"summary_format,omitempty"`
+	SummarySentences int      `json:"summary_sentences,omitempty"`
+	Types            []string `json:"types,omitempty"`
+	API              string   `json:"api,omitempty"`
+	APIRoot          string   `json:"api_root,omitempty"`
+	Auth             string   `json:"auth,omitempty"` // Reference to credentials; see Credentials
+	Comments         bool     `json:"comments,omitempty"`
+	Identity         string   `json:"identity,omitempty"`
+	FeedURL          string   `json:"feed_url,omitempty"`    // Of a feed source; see package feed
+	SitemapURL       string   `json:"sitemap_url,omitempty"` // Of a sitemap source; see package sitemap
+	Dir              string   `json:"dir,omitempty"`         // Of a markdown source; see package markdown
+	Actor            string   `json:"actor,omitempty"`       // Of an activitypub source (URL or @user@host); see package activitypub
+	RateLimit        Duration `json:"rate_limit,omitempty"`  // Pause following each request to the site; see Pause
+	TagPolicy        string   `json:"tag_policy,omitempty"`  // See ApplyTagPolicy
+	Disabled         bool     `json:"disabled,omitempty"`    // Per option "enabled"
+	Schedule         Duration `json:"schedule,omitempty"`    // Least interval between upserts of its posts
+
+	// Endpoint : /wp-json
+	Name           string `json:"name,omitempty"`
+	Description    string `json:"description,omitempty"`
+	URL            string `json:"url,omitempty"`
+	Home           string `json:"home,omitempty"`
+	GMTOffset      Offset `json:"gmt_offset,omitempty"`
+	TimezoneString string `json:"timezone_string,omitempty"` // IANA zone, e.g., "America/New_York"
+	Icon           string `json:"site_icon_url,omitempty"`   // Absolute URL
+}
+
+// Offset is the GMT offset (hours) of a site; fractional at some zones, e.g., 5.5 (+05:30).
+// WordPress renders it as either a JSON number or string.
+type Offset float64
+
+// UnmarshalJSON decodes an Offset from either a JSON number or string.
+func (o *Offset) UnmarshalJSON(bb []byte) error {
+	s := strings.Trim(string(bb), `"`)
+	if s == "" || s == "null" {
+		*o = 0
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return errors.Wrap(err, "gmt_offset")
+	}
+	*o = Offset(f)
+	return nil
+}
+
+// Status is that of the latest request to a site; its object (URI) and HTTP status code.
+type Status struct {
+	Object string `json:"object,omitempty"`
+	Code   int    `json:"code,omitempty"`
+}
+
+// Skip records an item (post, page, ...) of a site not mirrored, and the reason thereof.
+// An item failed (e.g., its fetch) declares its time of update (Retry), so that it is fetched anew per the next run.
+type Skip struct {
+	ID     int       `json:"id,omitempty"`
+	Link   string    `json:"link,omitempty"`
+	Reason string    `json:"reason,omitempty"`
+	Retry  time.Time `json:"retry,omitempty"`
+}
+
+// Location returns that of the site per its timezone_string (IANA zone), else per its gmt_offset.
+func (s Site) Location() *time.Location {
+	if s.TimezoneString != "" {
+		if loc, err := time.LoadLocation(s.TimezoneString); err == nil {
+			return loc
+		}
+		log.Printf("WARN : timezone_string '%s' @ %s : NOT FOUND\n", s.TimezoneString, s.HostURL)
+	}
+	if s.GMTOffset == 0 {
+		return time.UTC
+	}
+	return time.FixedZone("", int(math.Round(float64(s.GMTOffset)*3600)))
+}
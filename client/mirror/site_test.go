@@ -0,0 +1,49 @@
+package mirror
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+	_ "time/tzdata" // Zones regardless of host
+)
+
+func TestSiteLocation(t *testing.T) {
+	const date = "2022-07-11T14:22:07"
+	tests := []struct {
+		site Site
+		want string
+	}{
+		{Site{TimezoneString: "America/New_York", GMTOffset: -5}, "2022-07-11T18:22:07Z"},
+		{Site{TimezoneString: "Nowhere/Bogus", GMTOffset: 5.5}, "2022-07-11T08:52:07Z"},
+		{Site{GMTOffset: -4}, "2022-07-11T18:22:07Z"},
+		{Site{}, "2022-07-11T14:22:07Z"},
+	}
+	for _, tt := range tests {
+		d, _ := time.ParseInLocation("2006-01-02T15:04:05", date, tt.site.Location())
+		if got := d.UTC().Format(time.RFC3339); got != tt.want {
+			t.Errorf("%+v : %s, want %s", tt.site, got, tt.want)
+		}
+	}
+}
+
+func TestOffsetUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Offset
+		err  bool
+	}{
+		{`5.5`, 5.5, false},
+		{`"5.5"`, 5.5, false},
+		{`"-3"`, -3, false},
+		{`""`, 0, false},
+		{`null`, 0, false},
+		{`"x"`, 0, true},
+	}
+	for _, tt := range tests {
+		var o Offset
+		err := json.Unmarshal([]byte(tt.in), &o)
+		if (err != nil) != tt.err || o != tt.want {
+			t.Errorf("Offset(%s) = %v (err %v), want %v (err %v)", tt.in, o, err, tt.want, tt.err)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package mirror
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Summary formats (Site.SummaryFormat)
+const (
+	SummaryText     = "text" // Default
+	SummaryMarkdown = "markdown"
+	SummaryHTML     = "html"
+)
+
+// Summary synthesized from Content when an Excerpt is missing.
+const (
+	SummarySentences = 2
+	SummaryMaxChars  = 300
+)
+
+// moreLink matches the text of the "Continue reading" link appended to excerpts by WordPress themes.
+var moreLink = regexp.MustCompile(`(?i)^\s*(continue reading|read more|keep reading|read the rest)`)
+
+// HTMLToText renders an HTML fragment as plain text; entities decoded,
+// paragraphs delimited by a blank line, scripts and "Continue reading" links dropped.
+func HTMLToText(s string) string {
+	return render(s, false)
+}
+
+// HTMLToMarkdown renders an HTML fragment as Markdown.
+func HTMLToMarkdown(s string) string {
+	return render(s, true)
+}
+
+// render an HTML fragment as plain text, else as Markdown (md).
+func render(s string, md bool) string {
+	if strings.TrimSpace(s) == "" {
+		return ""
+	}
+	root := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(s), root)
+	if err != nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, n := range nodes {
+		writeNode(&b, n, md)
+	}
+	return tidy(b.String())
+}
+
+// writeNode writes the text of n and its descendants to b.
+func writeNode(b *strings.Builder, n *html.Node, md bool) {
+	switch n.Type {
+	case html.TextNode:
+		// Whitespace collapses, yet delimits adjacent (inline) elements.
+		t := strings.ReplaceAll(n.Data, "\u00a0", " ")
+		if r, _ := utf8.DecodeRuneInString(t); unicode.IsSpace(r) {
+			b.WriteString(" ")
+		}
+		b.WriteString(strings.Join(strings.Fields(t), " "))
+		if r, _ := utf8.DecodeLastRuneInString(t); unicode.IsSpace(r) {
+			b.WriteString(" ")
+		}
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+	children := func() {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			writeNode(b, c, md)
+		}
+	}
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Noscript, atom.Iframe, atom.Figure, atom.Img:
+		return
+	case atom.Br:
+		b.WriteString("\n")
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Ul, atom.Ol, atom.Table, atom.Tr:
+		b.WriteString("\n\n")
+		children()
+		b.WriteString("\n\n")
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		b.WriteString("\n\n")
+		if md {
+			b.WriteString(strings.Repeat("#", int(n.Data[1]-'0')) + " ")
+		}
+		children()
+		b.WriteString("\n\n")
+	case atom.Li:
+		b.WriteString("\n")
+		if md {
+			b.WriteString("- ")
+		}
+		children()
+	case atom.Blockquote:
+		b.WriteString("\n\n")
+		if md {
+			b.WriteString("> ")
+		}
+		children()
+		b.WriteString("\n\n")
+	case atom.A:
+		var t strings.Builder
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			writeNode(&t, c, md)
+		}
+		text := strings.TrimSpace(t.String())
+		if HasClass(n, "more-link", "read-more") || moreLink.MatchString(text) {
+			return
+		}
+		if href := Attr(n, "href"); md && href != "" && text != "" {
+			b.WriteString("[" + text + "](" + href + ")")
+			return
+		}
+		b.WriteString(t.String())
+	case atom.Strong, atom.B:
+		if md {
+			b.WriteString("**")
+			children()
+			b.WriteString("**")
+			return
+		}
+		children()
+	case atom.Em, atom.I:
+		if md {
+			b.WriteString("_")
+			children()
+			b.WriteString("_")
+			return
+		}
+		children()
+	default:
+		children()
+	}
+}
+
+// tidy collapses whitespace of each line, and runs of blank lines into one.
+func tidy(s string) string {
+	s = strings.ReplaceAll(s, "[…]", "…")
+	lines := []string{}
+	blank := true
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			if !blank {
+				lines = append(lines, "")
+			}
+			blank = true
+			continue
+		}
+		lines = append(lines, line)
+		blank = false
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// Summarize returns the first (n) sentences of (plain) text, truncated to (max) characters at a word boundary.
+func Summarize(text string, n, max int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if n > 0 {
+		count := 0
+		for i := 0; i < len(text)-1; i++ {
+			switch text[i] {
+			case '.', '!', '?':
+				if text[i+1] == ' ' {
+					count++
+				}
+			}
+			if count == n {
+				text = text[:i+1]
+				break
+			}
+		}
+	}
+	if max > 0 && utf8.RuneCountInString(text) > max {
+		rr := []rune(text)[:max]
+		t := string(rr)
+		if i := strings.LastIndex(t, " "); i > 0 {
+			t = t[:i]
+		}
+		text = strings.TrimRight(t, " ,;:.-") + "…"
+	}
+	return text
+}
+
+// Summary returns the Summary of a message from the excerpt (HTML) of its item, per Site.SummaryFormat;
+// synthesized from its content (HTML) if the excerpt is missing.
+func (a Adapter) Summary(excerpt, content string) string {
+	if HTMLToText(excerpt) == "" {
+		n := a.Site.SummarySentences
+		if n == 0 {
+			n = SummarySentences
+		}
+		return Summarize(HTMLToText(content), n, SummaryMaxChars)
+	}
+	switch a.Site.SummaryFormat {
+	case SummaryHTML:
+		if a.Cleaner != nil {
+			return a.Cleaner(excerpt)
+		}
+		return excerpt
+	case SummaryMarkdown:
+		return HTMLToMarkdown(excerpt)
+	}
+	return HTMLToText(excerpt)
+}
@@ -0,0 +1,63 @@
+package mirror
+
+import (
+	"testing"
+)
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		in, text, md string
+	}{
+		{"", "", ""},
+		{"<p>Fish &amp; chips</p><p>Second</p>", "Fish & chips\n\nSecond", "Fish & chips\n\nSecond"},
+		{`<p>Some <strong>bold</strong> and <a href="https://x.com/">a link</a>.</p>`,
+			"Some bold and a link.", "Some **bold** and [a link](https://x.com/)."},
+		{`<p>Excerpt [&hellip;] <a href="/p">Continue reading</a></p>`, "Excerpt …", "Excerpt …"},
+	}
+	for _, tt := range tests {
+		if got := HTMLToText(tt.in); got != tt.text {
+			t.Errorf("HTMLToText(%q)\n got: %q\nwant: %q", tt.in, got, tt.text)
+		}
+		if got := HTMLToMarkdown(tt.in); got != tt.md {
+			t.Errorf("HTMLToMarkdown(%q)\n got: %q\nwant: %q", tt.in, got, tt.md)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		text   string
+		n, max int
+		want   string
+	}{
+		{"One. Two! Three? Four.", 2, 0, "One. Two!"},
+		{"One.  Two.\n Three.", 5, 0, "One. Two. Three."},
+		{"No sentence end", 2, 0, "No sentence end"},
+		{"Version 1.2 is out. More soon.", 1, 0, "Version 1.2 is out."},
+		{"The quick brown fox jumps over the lazy dog.", 0, 20, "The quick brown fox…"},
+		{"Ünïcödé wörds ärë cöüntëd äs rünës.", 0, 14, "Ünïcödé wörds…"},
+	}
+	for _, tt := range tests {
+		if got := Summarize(tt.text, tt.n, tt.max); got != tt.want {
+			t.Errorf("Summarize(%q, %d, %d) = %q, want %q", tt.text, tt.n, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestSummary(t *testing.T) {
+	tests := []struct {
+		format, excerpt, content, want string
+	}{
+		{"", "<p>The <em>excerpt</em>.</p>", "<p>Content.</p>", "The excerpt."},
+		{SummaryMarkdown, "<p>The <em>excerpt</em>.</p>", "", "The _excerpt_."},
+		{SummaryHTML, "<p style=\"x\">The excerpt.</p>", "", "<p>The excerpt.</p>"},
+		{"", "", "<p>First. Second. Third.</p>", "First. Second."},
+	}
+	for _, tt := range tests {
+		site := Site{HostURL: "https://x.com", SummaryFormat: tt.format, Cleaners: []string{"styles"}}
+		a := NewAdapter(nil, &site)
+		if got := a.Summary(tt.excerpt, tt.content); got != tt.want {
+			t.Errorf("%q : Summary(%q, %q) = %q, want %q", tt.format, tt.excerpt, tt.content, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package mirror
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	want := time.Date(2022, 7, 11, 14, 22, 7, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"Mon, 11 Jul 2022 14:22:07 +0000", want},
+		{"Mon, 11 Jul 2022 10:22:07 -0400", want},
+		{"Mon, 11 Jul 2022 14:22:07 GMT", want},
+		{"Mon, 11 Jul 2022 14:22 +0000", want.Truncate(time.Minute)},
+		{"Mon, 11 Jul 22 14:22:07 +0000", want},
+		{"11 Jul 2022 14:22:07 +0000", want},
+		{"2022-07-11T14:22:07Z", want},
+		{"2022-07-11T16:22:07.000+02:00", want},
+		{"2022-07-11T14:22:07", want},
+		{"2022-07-11 14:22:07", want},
+		{"2022-07-11", want.Truncate(24 * time.Hour)},
+		{"  2022-07-11T14:22:07Z\n", want},
+		{"", time.Time{}},
+		{"yesterday", time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := ParseTime(tt.in); !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package client
 
 import (
 	"log"
+	"net/http"
 	"time"
 
 	"github.com/ardanlabs/conf"
@@ -37,6 +38,10 @@ const (
 const (
 	JSON = "application/json"
 	HTML = "text/html"
+	XML  = "application/rss+xml, application/atom+xml, application/xml;q=0.9, text/xml;q=0.8"
+	IMG  = "image/webp, image/png, image/*;q=0.8"
+
+	ACTIVITY = `application/activity+json, application/ld+json; profile="https://www.w3.org/ns/activitystreams";q=0.9`
 )
 
 type CSRF struct {
@@ -45,9 +50,10 @@ type CSRF struct {
 
 // Response is the return of all (exported) client function calls.
 type Response struct {
-	Body  string `json:"body,omitempty"`
-	Code  int    `json:"code,omitempty"`
-	Error string `json:"error,omitempty"`
+	Body   string      `json:"body,omitempty"`
+	Code   int         `json:"code,omitempty"`
+	Error  string      `json:"error,omitempty"`
+	Header http.Header `json:"-"`
 	// Error `json:"error,omitempty"`
 }
 
@@ -97,6 +103,7 @@ type Client struct {
 	Key        string        `json:"key,omitempty"`
 	UserAgent  string        `json:"user_agent,omitempty"`
 	Timeout    time.Duration `json:"timeout,omitempty"`
+	Workers    int           `json:"workers,omitempty"` // Of concurrent requests to (distinct) sites
 	TraceLevel int           `json:"trace_level,omitempty"`
 	TraceDump  bool          `json:"trace_dump,omitempty"`
 	TraceFpath string        `json:"trace_fpath,omitempty"`
@@ -165,6 +172,12 @@ type Message struct {
 	Tags    []string `json:"tags,omitempty"`
 	URI     string   `json:"uri,omitempty"`
 
+	// Reply (short-form) : ThreadID is that of the thread-root (long-form) message,
+	// and ParentID that of the message replied to.
+	ThreadID string `json:"thread_id,omitempty"`
+	ParentID string `json:"parent_id,omitempty"`
+	Author   string `json:"author,omitempty"` // Display name of (external) author
+
 	// DateCreate time.Time `db:"date_create" json:"date_create,omitempty"`
 	//... Not exist @ uqrate mirror
 	DateUpdate time.Time `json:"date_update,omitempty"`
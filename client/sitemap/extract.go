@@ -0,0 +1,389 @@
+package sitemap
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sempernow/uqc/client/mirror"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Page is that extracted from an HTML document; an article if IsArticle.
+type Page struct {
+	URL         string    `json:"url,omitempty"` // Canonical, if declared
+	Title       string    `json:"title,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Content     string    `json:"content,omitempty"` // HTML
+	Author      string    `json:"author,omitempty"`
+	Image       string    `json:"image,omitempty"`
+	Section     string    `json:"section,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Published   time.Time `json:"published,omitempty"`
+	Modified    time.Time `json:"modified,omitempty"`
+	IsArticle   bool      `json:"is_article,omitempty"`
+
+	// Of the site
+	SiteName string `json:"site_name,omitempty"`
+	Icon     string `json:"icon,omitempty"`
+}
+
+// ArticleTypes are those of schema.org (JSON-LD @type) regarded as an article.
+var ArticleTypes = []string{
+	"Article", "NewsArticle", "BlogPosting", "Report", "ScholarlyArticle",
+	"TechArticle", "AnalysisNewsArticle", "OpinionNewsArticle", "ReportageNewsArticle", "LiveBlogPosting",
+}
+
+// ContentClasses are those of elements commonly containing the main content of an article.
+var ContentClasses = []string{"entry-content", "post-content", "article-content", "article-body", "post-body", "story-body"}
+
+// MinParagraph is the (rune) length of the shortest paragraph scored as content.
+const MinParagraph = 25
+
+// boilerplate are elements pruned from the extracted content.
+var boilerplate = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Nav: true, atom.Aside: true,
+	atom.Footer: true, atom.Header: true, atom.Form: true, atom.Button: true, atom.Iframe: true,
+}
+
+// Extract extracts the page (article) of an HTML document per, in order of precedence,
+// its OpenGraph (and article:*) meta tags, its JSON-LD (schema.org) Article, and its markup;
+// the main content per its <article> element, else readability-style heuristics.
+func Extract(doc string) (*Page, error) {
+	root, err := html.Parse(strings.NewReader(doc))
+	if err != nil {
+		return nil, err
+	}
+	p := &Page{}
+	meta := map[string]string{}
+	var (
+		title    string
+		times    string
+		lds      []string
+		articles []*html.Node
+		classed  *html.Node
+	)
+	mirror.Walk(root, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.Meta:
+			key := mirror.Attr(n, "property")
+			if key == "" {
+				key = mirror.Attr(n, "name")
+			}
+			key = strings.ToLower(key)
+			val := strings.TrimSpace(mirror.Attr(n, "content"))
+			if key == "" || val == "" {
+				break
+			}
+			if key == "article:tag" {
+				p.Tags = append(p.Tags, val)
+			} else if _, ok := meta[key]; !ok {
+				meta[key] = val
+			}
+		case atom.Link:
+			rel := strings.ToLower(mirror.Attr(n, "rel"))
+			switch {
+			case rel == "canonical":
+				p.URL = mirror.Attr(n, "href")
+			case p.Icon == "" && (rel == "icon" || rel == "shortcut icon" || rel == "apple-touch-icon"):
+				p.Icon = mirror.Attr(n, "href")
+			}
+		case atom.Title:
+			if title == "" {
+				title = text(n)
+			}
+		case atom.Time:
+			if times == "" {
+				times = mirror.Attr(n, "datetime")
+			}
+		case atom.Script:
+			if strings.Contains(mirror.Attr(n, "type"), "ld+json") {
+				lds = append(lds, text(n))
+			}
+			return false
+		case atom.Article:
+			articles = append(articles, n)
+		default:
+			if classed == nil && n.Type == html.ElementNode &&
+				(mirror.Attr(n, "itemprop") == "articleBody" || mirror.HasClass(n, ContentClasses...)) {
+				classed = n
+			}
+		}
+		return true
+	})
+
+	ld := jsonLD(lds)
+	p.Title = first(meta["og:title"], ldString(ld["headline"]), meta["twitter:title"], title)
+	p.Description = first(meta["og:description"], ldString(ld["description"]), meta["description"])
+	p.Image = first(meta["og:image"], ldImage(ld["image"]))
+	p.Author = first(meta["author"], meta["article:author"], ldAuthor(ld["author"]))
+	if strings.HasPrefix(p.Author, "http") {
+		p.Author = ldAuthor(ld["author"])
+	}
+	p.Section = first(meta["article:section"], ldString(ld["articleSection"]))
+	p.SiteName = first(meta["og:site_name"], meta["application-name"])
+	p.Published = mirror.ParseTime(first(meta["article:published_time"], ldString(ld["datePublished"]), times))
+	p.Modified = mirror.ParseTime(first(meta["article:modified_time"], meta["og:updated_time"], ldString(ld["dateModified"])))
+	if len(p.Tags) == 0 {
+		p.Tags = ldKeywords(ld["keywords"])
+	}
+	p.IsArticle = meta["og:type"] == "article" || ld != nil || len(articles) > 0
+
+	main := largest(articles)
+	if main == nil {
+		main = classed
+	}
+	if main == nil {
+		main = readable(root)
+	}
+	if main != nil {
+		p.Content = render(main)
+	}
+	if p.Content == "" {
+		p.IsArticle = false
+	}
+	return p, nil
+}
+
+// ----------------------------------------------------------------------------
+// JSON-LD
+
+// jsonLD returns the (first) Article object of the JSON-LD blocks of a document; nil if none.
+func jsonLD(blocks []string) map[string]interface{} {
+	for _, b := range blocks {
+		var v interface{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(b)), &v); err != nil {
+			continue
+		}
+		if obj := findArticle(v); obj != nil {
+			return obj
+		}
+	}
+	return nil
+}
+
+// findArticle searches a JSON-LD value, of any (@graph) nesting, for an object of ArticleTypes.
+func findArticle(v interface{}) map[string]interface{} {
+	switch x := v.(type) {
+	case []interface{}:
+		for _, el := range x {
+			if obj := findArticle(el); obj != nil {
+				return obj
+			}
+		}
+	case map[string]interface{}:
+		if isArticle(x["@type"]) {
+			return x
+		}
+		if g, ok := x["@graph"]; ok {
+			return findArticle(g)
+		}
+	}
+	return nil
+}
+
+// isArticle reports whether a JSON-LD @type (string or list) is of ArticleTypes.
+func isArticle(t interface{}) bool {
+	switch x := t.(type) {
+	case string:
+		for _, at := range ArticleTypes {
+			if x == at {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, el := range x {
+			if isArticle(el) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func ldString(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return strings.TrimSpace(x)
+	case []interface{}:
+		if len(x) > 0 {
+			return ldString(x[0])
+		}
+	}
+	return ""
+}
+
+// ldImage returns the URL of a JSON-LD image; a URL, an ImageObject, or a list thereof.
+func ldImage(v interface{}) string {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		return ldString(x["url"])
+	case []interface{}:
+		if len(x) > 0 {
+			return ldImage(x[0])
+		}
+	}
+	return ldString(v)
+}
+
+// ldAuthor returns the name of a JSON-LD author; a name, a Person, or a list thereof.
+func ldAuthor(v interface{}) string {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		return ldString(x["name"])
+	case []interface{}:
+		if len(x) > 0 {
+			return ldAuthor(x[0])
+		}
+	}
+	return ldString(v)
+}
+
+// ldKeywords returns the list of JSON-LD keywords; a comma-delimited string, or a list.
+func ldKeywords(v interface{}) []string {
+	ss := []string{}
+	switch x := v.(type) {
+	case string:
+		for _, s := range strings.Split(x, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				ss = append(ss, s)
+			}
+		}
+	case []interface{}:
+		for _, el := range x {
+			if s := ldString(el); s != "" {
+				ss = append(ss, s)
+			}
+		}
+	}
+	return ss
+}
+
+// ----------------------------------------------------------------------------
+// Content
+
+// largest returns the node of most text; nil if none.
+func largest(nodes []*html.Node) *html.Node {
+	var (
+		best *html.Node
+		most int
+	)
+	for _, n := range nodes {
+		if l := textLen(n); l > most {
+			best, most = n, l
+		}
+	}
+	return best
+}
+
+// readable returns the node most likely the main content of a document, per a readability-style score:
+// that of its paragraphs (length and commas), shared in part with its grandparent,
+// discounted per density of links; nil if no paragraph qualifies.
+func readable(root *html.Node) *html.Node {
+	scores := map[*html.Node]float64{}
+	mirror.Walk(root, func(n *html.Node) bool {
+		if boilerplate[n.DataAtom] {
+			return false
+		}
+		if n.DataAtom != atom.P && n.DataAtom != atom.Pre && n.DataAtom != atom.Blockquote {
+			return true
+		}
+		t := text(n)
+		l := utf8.RuneCountInString(t)
+		if l < MinParagraph || n.Parent == nil {
+			return false
+		}
+		score := 1 + float64(strings.Count(t, ",")) + math.Min(float64(l/100), 3)
+		scores[n.Parent] += score
+		if gp := n.Parent.Parent; gp != nil {
+			scores[gp] += score / 2
+		}
+		return false
+	})
+	var (
+		best *html.Node
+		top  float64
+	)
+	for n, score := range scores {
+		if l := textLen(n); l > 0 {
+			score *= 1 - float64(linkTextLen(n))/float64(l)
+		}
+		if score > top {
+			best, top = n, score
+		}
+	}
+	return best
+}
+
+// render returns the HTML of the children of a node, pruned of boilerplate.
+func render(n *html.Node) string {
+	var buf bytes.Buffer
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if boilerplate[c.DataAtom] {
+			continue
+		}
+		prune(c)
+		html.Render(&buf, c)
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// prune removes boilerplate descendants of a node.
+func prune(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if boilerplate[c.DataAtom] {
+			n.RemoveChild(c)
+		} else {
+			prune(c)
+		}
+		c = next
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Helpers
+
+// text returns the (whitespace-collapsed) text of a node.
+func text(n *html.Node) string {
+	var b strings.Builder
+	mirror.Walk(n, func(c *html.Node) bool {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+			b.WriteString(" ")
+		}
+		return true
+	})
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func textLen(n *html.Node) int {
+	return utf8.RuneCountInString(text(n))
+}
+
+// linkTextLen returns the (rune) length of the text of links of a node.
+func linkTextLen(n *html.Node) int {
+	l := 0
+	mirror.Walk(n, func(c *html.Node) bool {
+		if c.DataAtom == atom.A {
+			l += textLen(c)
+			return false
+		}
+		return true
+	})
+	return l
+}
+
+// first returns the first non-empty of ss.
+func first(ss ...string) string {
+	for _, s := range ss {
+		if s = strings.TrimSpace(s); s != "" {
+			return s
+		}
+	}
+	return ""
+}
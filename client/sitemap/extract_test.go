@@ -0,0 +1,100 @@
+package sitemap
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name      string
+		doc       string
+		article   bool
+		title     string
+		author    string
+		section   string
+		tags      []string
+		published time.Time
+		modified  time.Time
+		content   string // Contained
+		pruned    string // Not contained
+	}{
+		{
+			name: "opengraph",
+			doc: `<html><head><title>Fallback</title>
+<meta property="og:type" content="article"><meta property="og:title" content="The Title">
+<meta property="og:description" content="About it."><meta property="og:image" content="/lead.jpg">
+<meta name="author" content="Jane"><meta property="article:section" content="Tech">
+<meta property="article:tag" content="go"><meta property="article:tag" content="web">
+<meta property="article:published_time" content="2024-01-02T03:04:05Z">
+<meta property="article:modified_time" content="2024-01-03T03:04:05+01:00">
+<link rel="canonical" href="https://x.com/the-title/"></head>
+<body><header>Site header</header><article><h1>The Title</h1><p>The body of the article.</p><script>track()</script></article>
+<footer>Site footer</footer></body></html>`,
+			article:   true,
+			title:     "The Title",
+			author:    "Jane",
+			section:   "Tech",
+			tags:      []string{"go", "web"},
+			published: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			modified:  time.Date(2024, 1, 3, 2, 4, 5, 0, time.UTC),
+			content:   "<p>The body of the article.</p>",
+			pruned:    "track()",
+		},
+		{
+			name: "json-ld",
+			doc: `<html><head><title>Page</title><script type="application/ld+json">
+{"@context": "https://schema.org", "@graph": [{"@type": "WebSite"}, {"@type": ["NewsArticle"], "headline": "Graph Headline",
+"author": [{"@type": "Person", "name": "Bob"}], "keywords": "alpha, beta", "datePublished": "2024-02-01",
+"image": {"@type": "ImageObject", "url": "https://x.com/ld.jpg"}}]}</script></head>
+<body><div class="post-content"><p>Content of the classed element.</p></div></body></html>`,
+			article:   true,
+			title:     "Graph Headline",
+			author:    "Bob",
+			tags:      []string{"alpha", "beta"},
+			published: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			content:   "Content of the classed element.",
+		},
+		{
+			name: "readable",
+			doc: `<html><head><title>Plain</title><meta property="og:type" content="article"></head><body>
+<nav><p>Home, About, Contact, and a list of links long enough to be scored.</p></nav>
+<div id="main"><p>The first paragraph, of commas, of length, well beyond the minimum.</p>
+<p>The second paragraph, also long enough to be regarded as content of the page.</p></div>
+<div id="side"><p><a href="/a">A paragraph of nothing but a link, long enough to score.</a></p></div></body></html>`,
+			article: true,
+			title:   "Plain",
+			content: "The second paragraph",
+			pruned:  "Contact",
+		},
+		{
+			name:  "not an article",
+			doc:   `<html><head><title>Home</title><meta property="og:type" content="website"></head><body><p>Hi.</p></body></html>`,
+			title: "Home",
+		},
+	}
+	for _, tt := range tests {
+		p, err := Extract(tt.doc)
+		if err != nil {
+			t.Errorf("%s : %s", tt.name, err.Error())
+			continue
+		}
+		if p.IsArticle != tt.article || p.Title != tt.title {
+			t.Errorf("%s : article %v, title %q, want %v, %q", tt.name, p.IsArticle, p.Title, tt.article, tt.title)
+		}
+		if p.Author != tt.author || p.Section != tt.section || fmt.Sprint(p.Tags) != fmt.Sprint(tt.tags) {
+			t.Errorf("%s : author %q, section %q, tags %v", tt.name, p.Author, p.Section, p.Tags)
+		}
+		if !p.Published.Equal(tt.published) || !p.Modified.Equal(tt.modified) {
+			t.Errorf("%s : published %v, modified %v", tt.name, p.Published, p.Modified)
+		}
+		if !strings.Contains(p.Content, tt.content) {
+			t.Errorf("%s : content %q, want %q therein", tt.name, p.Content, tt.content)
+		}
+		if tt.pruned != "" && strings.Contains(p.Content, tt.pruned) {
+			t.Errorf("%s : content %q, want %q pruned", tt.name, p.Content, tt.pruned)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+// Package sitemap is the source adapter of sites having neither API nor full-content feed,
+// per crawl of their sitemap (sitemaps.org) and extraction of each article from its HTML page.
+package sitemap
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"html"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
+)
+
+// DefaultPath is that of the sitemap of a site declaring none (Site.SitemapURL), neither at its robots.txt.
+const DefaultPath = "/sitemap.xml"
+
+// Limits per run : MaxDepth of nested sitemap indexes; MaxPages fetched (newest first).
+const (
+	MaxDepth = 3
+	MaxPages = 100
+)
+
+// Reasons a page of the sitemap is not mirrored.
+const (
+	SkipNotArticle = "not an article"
+	SkipFetch      = "fetch failed"
+)
+
+// Entry is a <url> (page) or <sitemap> (of an index) of a sitemap.
+type Entry struct {
+	Loc     string    `xml:"loc"`
+	LastMod time.Time `xml:"-"`
+	Mod     string    `xml:"lastmod"`
+}
+
+// URLSet is a sitemap, else (sitemapindex) an index of sitemaps.
+type URLSet struct {
+	XMLName  xml.Name
+	URLs     []Entry `xml:"url"`
+	Sitemaps []Entry `xml:"sitemap"`
+}
+
+// Sitemap is the source of a site per its sitemap.
+// Each page extracted as an article (see Extract) maps to a message of its canonical URL (URI), title,
+// content prefixed by its (OpenGraph) image, description, section (category), tags and author (tags),
+// and its date modified, else published. A page has no id other than its URI, its identity regardless of Site.Identity.
+type Sitemap struct {
+	mirror.Adapter
+	Pages []Page
+}
+
+// New returns the Sitemap source of a site.
+func New(env *client.Env, site *mirror.Site) *Sitemap {
+	return &Sitemap{Adapter: mirror.NewAdapter(env, site)}
+}
+
+// Describe merges the metadata of the home page of the site into its Site record.
+func (s *Sitemap) Describe() {
+	site := s.Site
+	body, err := s.get(site.HostURL, client.HTML)
+	if err != nil {
+		site.Error = err.Error()
+		return
+	}
+	p, err := Extract(body)
+	if err != nil {
+		site.Error = err.Error()
+		return
+	}
+	site.Name = first(p.SiteName, p.Title)
+	site.Description = p.Description
+	site.URL = first(p.URL, site.HostURL)
+	site.Home = site.URL
+	if p.Icon != "" {
+		site.Icon = mirror.Resolve(site.HostURL, p.Icon)
+	}
+}
+
+// Fetch retrieves the articles of the site modified since the checkpoint (since); all if zero,
+// per lastmod of each page of its sitemap. Pages lacking lastmod are fetched only if since is zero.
+// Pages other than articles are recorded at Site.Skipped, as are those failed (fetch or extract), to be fetched anew.
+func (s *Sitemap) Fetch(since time.Time) {
+	site := s.Site
+	entries, err := s.entries(s.URL(), since, 0)
+	if err != nil {
+		site.Error = err.Error()
+		return
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastMod.After(entries[j].LastMod)
+	})
+	if len(entries) > MaxPages {
+		log.Printf("INFO : sitemap @ %s : pages: %d : fetching newest %d\n", site.HostURL, len(entries), MaxPages)
+		entries = entries[:MaxPages]
+	}
+	for _, e := range entries {
+		var p *Page
+		body, err := s.get(e.Loc, client.HTML)
+		if err == nil {
+			p, err = Extract(body)
+		}
+		if err != nil {
+			log.Printf("ERR : page @ %s : %s\n", e.Loc, err.Error())
+			site.Skipped = append(site.Skipped, mirror.Skip{Link: e.Loc, Reason: SkipFetch, Retry: e.LastMod})
+			continue
+		}
+		if !p.IsArticle {
+			log.Printf("INFO : SKIP page @ %s : %s\n", e.Loc, SkipNotArticle)
+			site.Skipped = append(site.Skipped, mirror.Skip{Link: e.Loc, Reason: SkipNotArticle})
+			continue
+		}
+		if p.URL == "" {
+			p.URL = e.Loc
+		}
+		p.URL = mirror.Resolve(e.Loc, p.URL)
+		if p.Modified.IsZero() {
+			p.Modified = e.LastMod
+		}
+		s.Pages = append(s.Pages, *p)
+	}
+}
+
+// Messages maps the extracted articles into Uqrate messages.
+func (s *Sitemap) Messages() []client.Message {
+	msgs := []client.Message{}
+	for _, p := range s.Pages {
+		msg := s.PageToMsg(&p)
+		if msg.ID == "" {
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// PageToMsg denormalizes an extracted article into a Uqrate message.
+func (s *Sitemap) PageToMsg(p *Page) client.Message {
+	msg := client.Message{}
+	site := s.Site
+
+	msg.ChnID = site.ChnID
+	var err error
+	if msg.URI, err = mirror.LinkToURI(p.URL, site.HostURL); err != nil {
+		log.Printf("ERR : LinkToURI : page %s : %s\n", p.URL, err.Error())
+		return client.Message{}
+	}
+	msg.ID = mirror.MessageID(msg.ChnID, msg.URI)
+	if msg.ID == "" {
+		log.Printf("ERR : UUIDv5 fail : URI: %s .\n", msg.URI)
+		return client.Message{URI: msg.URI}
+	}
+
+	msg.Title = p.Title
+	msg.Body = p.Content
+
+	// Prepend the (OpenGraph) image unless the body already renders it.
+	if src := p.Image; src != "" && !mirror.Renders(msg.Body, src) {
+		msg.Body = mirror.Figure(mirror.Resolve(p.URL, src), "", 0, 0) + msg.Body
+	}
+	if s.Cleaner != nil {
+		msg.Body = s.Cleaner(msg.Body)
+	}
+
+	// The description of a page is plain text.
+	msg.Summary = s.Summary(html.EscapeString(p.Description), p.Content)
+
+	if p.Section != "" {
+		msg.Cats = []string{p.Section}
+	}
+	msg.Tags = append(msg.Tags, p.Tags...)
+	msg.Tags = mirror.AuthorTag(msg.Tags, p.Author)
+
+	mirror.Sanitize(msg.Cats)
+	mirror.Sanitize(msg.Tags)
+
+	msg.DateUpdate = mirror.DateUpdate(p.Modified, p.Published)
+
+	return msg
+}
+
+// URL returns that of the sitemap of the site: that declared (Site.SitemapURL),
+// else the first declared at its robots.txt, else that of DefaultPath.
+func (s *Sitemap) URL() string {
+	site := s.Site
+	if site.SitemapURL == "" {
+		site.SitemapURL = s.robots()
+	}
+	if site.SitemapURL == "" {
+		site.SitemapURL = strings.TrimSuffix(site.HostURL, "/") + DefaultPath
+	}
+	return site.SitemapURL
+}
+
+// robots returns the (first) sitemap declared at robots.txt of the site; empty if none.
+func (s *Sitemap) robots() string {
+	body, err := s.get(strings.TrimSuffix(s.Site.HostURL, "/")+"/robots.txt", client.HTML)
+	if err != nil {
+		return ""
+	}
+	sc := bufio.NewScanner(strings.NewReader(body))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if kv := strings.SplitN(line, ":", 2); len(kv) == 2 && strings.EqualFold(kv[0], "sitemap") {
+			return strings.TrimSpace(kv[1])
+		}
+	}
+	return ""
+}
+
+// entries returns the pages of a sitemap (url) modified since; recursing into sitemap indexes (depth).
+// Only pages of the site (host) are returned.
+func (s *Sitemap) entries(url string, since time.Time, depth int) ([]Entry, error) {
+	if depth > MaxDepth {
+		return nil, errors.Errorf("sitemap index nested beyond %d @ %s", MaxDepth, url)
+	}
+	body, err := s.get(url, client.XML)
+	if err != nil {
+		return nil, err
+	}
+	set, err := Parse([]byte(body))
+	if err != nil {
+		log.Printf("ERR : Parse sitemap @ %s : %s\n", url, err.Error())
+		return nil, err
+	}
+	fresh := func(e Entry) bool {
+		if since.IsZero() {
+			return true
+		}
+		return !e.LastMod.IsZero() && e.LastMod.After(since)
+	}
+	host := mirror.Host(s.Site.HostURL)
+	ee := []Entry{}
+	for _, sm := range set.Sitemaps {
+		if !fresh(sm) && !sm.LastMod.IsZero() {
+			continue
+		}
+		sub, err := s.entries(mirror.Resolve(url, sm.Loc), since, depth+1)
+		if err != nil {
+			log.Printf("WARN : sitemap @ %s : %s\n", sm.Loc, err.Error())
+			continue
+		}
+		ee = append(ee, sub...)
+	}
+	for _, e := range set.URLs {
+		e.Loc = mirror.Resolve(url, e.Loc)
+		if !fresh(e) || mirror.Host(e.Loc) != host {
+			continue
+		}
+		ee = append(ee, e)
+	}
+	return ee, nil
+}
+
+// Parse decodes a sitemap, or sitemap index, of either plain or gzipped XML.
+func Parse(bb []byte) (*URLSet, error) {
+	if len(bb) > 1 && bb[0] == 0x1f && bb[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(bb))
+		if err != nil {
+			return nil, errors.Wrap(err, "gunzip")
+		}
+		if bb, err = io.ReadAll(zr); err != nil {
+			return nil, errors.Wrap(err, "gunzip")
+		}
+	}
+	set := URLSet{}
+	if err := xml.Unmarshal(bb, &set); err != nil {
+		return nil, errors.Wrap(err, "decoding sitemap")
+	}
+	switch set.XMLName.Local {
+	case "urlset", "sitemapindex":
+	default:
+		return nil, errors.Errorf("not a sitemap : root element <%s>", set.XMLName.Local)
+	}
+	for i := range set.URLs {
+		set.URLs[i].Loc = strings.TrimSpace(set.URLs[i].Loc)
+		set.URLs[i].LastMod = mirror.ParseTime(set.URLs[i].Mod)
+	}
+	for i := range set.Sitemaps {
+		set.Sitemaps[i].Loc = strings.TrimSpace(set.Sitemaps[i].Loc)
+		set.Sitemaps[i].LastMod = mirror.ParseTime(set.Sitemaps[i].Mod)
+	}
+	return &set, nil
+}
+
+// get returns the body of a GET (url) of content type (cType).
+func (s *Sitemap) get(url, cType string) (string, error) {
+	rsp := s.Env.Get(url, cType)
+	s.Site.Pause()
+
+	s.Site.Status.Object = url
+	s.Site.Status.Code = rsp.Code
+
+	if rsp.Error != "" {
+		return "", errors.New(rsp.Error)
+	}
+	if rsp.Body == "" {
+		return "", errors.New("GET returned nothing")
+	}
+	return rsp.Body, nil
+}
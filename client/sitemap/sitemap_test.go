@@ -0,0 +1,147 @@
+package sitemap
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
+)
+
+const testChnID = "e2b3e5b4-1b0e-4c8e-8f1b-3c1b7e0e5a11"
+
+// Pages of a title; an article, else (website) not.
+const (
+	testArticle = `<html><head><title>%s</title><meta property="og:type" content="article"></head>
+<body><article><p>The body of the page, long enough to be regarded as content.</p></article></body></html>`
+	testWebsite = `<html><head><title>%s</title><meta property="og:type" content="website"></head><body><p>Hi.</p></body></html>`
+)
+
+// testSitemap returns a Sitemap of a site at an httptest server whose robots.txt declares an index of two sitemaps,
+// and the paths of pages fetched (GET) thereof.
+func testSitemap(t *testing.T) (*Sitemap, func() []string) {
+	t.Helper()
+	var (
+		mu      sync.Mutex
+		fetched []string
+		srv     *httptest.Server
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "User-agent: *\nSitemap: %s/index.xml\n", srv.URL)
+	})
+	mux.HandleFunc("/index.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0"?><sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+<sitemap><loc>/posts.xml</loc><lastmod>2024-01-03</lastmod></sitemap>
+<sitemap><loc>/pages.xml</loc></sitemap></sitemapindex>`)
+	})
+	mux.HandleFunc("/posts.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+<url><loc>/old</loc><lastmod>2024-01-01</lastmod></url>
+<url><loc>/new</loc><lastmod>2024-01-03T12:00:00Z</lastmod></url>
+<url><loc>https://elsewhere.com/post</loc><lastmod>2024-01-03</lastmod></url></urlset>`)
+	})
+	mux.HandleFunc("/pages.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+<url><loc>/about</loc><lastmod>2024-01-02</lastmod></url>
+<url><loc>/undated</loc></url></urlset>`)
+	})
+	for path, page := range map[string]string{"/old": testArticle, "/new": testArticle, "/undated": testArticle, "/about": testWebsite} {
+		path, page := path, page
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			fetched = append(fetched, r.URL.Path)
+			mu.Unlock()
+			fmt.Fprintf(w, page, strings.TrimPrefix(path, "/"))
+		})
+	}
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	env := &client.Env{
+		Logger: log.New(io.Discard, "", 0),
+		Cache:  t.TempDir(),
+		Client: client.Client{Timeout: 5 * time.Second},
+	}
+	site := &mirror.Site{
+		UserHandle: "test", HostURL: srv.URL, ChnID: testChnID, Source: "sitemap",
+		RateLimit: mirror.Duration(time.Millisecond),
+	}
+	return New(env, site), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		ss := append([]string{}, fetched...)
+		sort.Strings(ss)
+		return ss
+	}
+}
+
+func TestMessagesSince(t *testing.T) {
+	tests := []struct {
+		name    string
+		since   time.Time
+		fetched []string
+		titles  []string // Newest first
+		dates   []time.Time
+		skipped int
+	}{
+		{
+			name:    "all",
+			fetched: []string{"/about", "/new", "/old", "/undated"},
+			titles:  []string{"new", "old", "undated"},
+			dates:   []time.Time{time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), {}},
+			skipped: 1,
+		},
+		{
+			name:    "since",
+			since:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			fetched: []string{"/about", "/new"},
+			titles:  []string{"new"},
+			dates:   []time.Time{time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)},
+			skipped: 1,
+		},
+		{
+			name:  "none since",
+			since: time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		s, fetched := testSitemap(t)
+		s.Fetch(tt.since)
+		if s.Site.Error != "" {
+			t.Errorf("%s : %s", tt.name, s.Site.Error)
+			continue
+		}
+		if got := fetched(); fmt.Sprint(got) != fmt.Sprint(tt.fetched) {
+			t.Errorf("%s : fetched %v, want %v", tt.name, got, tt.fetched)
+		}
+		if len(s.Site.Skipped) != tt.skipped {
+			t.Errorf("%s : skipped %+v, want %d", tt.name, s.Site.Skipped, tt.skipped)
+		}
+		msgs := s.Messages()
+		titles := []string{}
+		for _, msg := range msgs {
+			titles = append(titles, msg.Title)
+		}
+		if fmt.Sprint(titles) != fmt.Sprint(tt.titles) {
+			t.Errorf("%s : messages %v, want %v", tt.name, titles, tt.titles)
+			continue
+		}
+		for i, msg := range msgs {
+			if msg.ID != mirror.MessageID(testChnID, msg.URI) || msg.URI != "/"+msg.Title {
+				t.Errorf("%s : %s : uri %q, id %q", tt.name, msg.Title, msg.URI, msg.ID)
+			}
+			if !tt.dates[i].IsZero() && !msg.DateUpdate.Equal(tt.dates[i]) {
+				t.Errorf("%s : %s : date %v, want %v (lastmod)", tt.name, msg.Title, msg.DateUpdate, tt.dates[i])
+			}
+		}
+	}
+}
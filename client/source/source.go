@@ -0,0 +1,133 @@
+// Package source abstracts the platforms whose sites feed Uqrate channels (mirrors),
+// each by its adapter, the first of which is that of package wordpress.
+package source
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sempernow/kit/types/convert"
+	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/activitypub"
+	"github.com/sempernow/uqc/client/feed"
+	"github.com/sempernow/uqc/client/ghost"
+	"github.com/sempernow/uqc/client/jsonfeed"
+	"github.com/sempernow/uqc/client/markdown"
+	"github.com/sempernow/uqc/client/mirror"
+	"github.com/sempernow/uqc/client/sitemap"
+	"github.com/sempernow/uqc/client/wordpress"
+)
+
+// Types of source (Site.Source)
+const (
+	WordPress   = "wordpress"   // Default
+	Feed        = "feed"        // RSS or Atom
+	Ghost       = "ghost"       // Content API
+	JSONFeed    = "jsonfeed"    // JSON Feed 1.1 (or 1.0)
+	Sitemap     = "sitemap"     // Sitemap crawl and article extraction
+	Markdown    = "markdown"    // Local directory of Markdown files
+	ActivityPub = "activitypub" // Outbox of a fediverse actor
+)
+
+// Prefixes of the cache keys of a site's checkpoint, and of its last run (upsert).
+const (
+	CacheKeyCheckpointPrefix = "checkpoint."
+	CacheKeyLastRunPrefix    = "lastrun."
+)
+
+// Source is the adapter of a site (mirror.Site) of any platform,
+// from which its (Uqrate) channel is fed.
+type Source interface {
+	// Describe merges the metadata of the site (name, description, url, ...) into its Site record.
+	Describe()
+	// Fetch retrieves the items of the site updated since the checkpoint (since); all if zero.
+	Fetch(since time.Time)
+	// Messages maps the fetched items into Uqrate messages.
+	Messages() []client.Message
+}
+
+// Committer is implemented by a Source tracking which of its items were upserted (e.g., per content hash),
+// so those unchanged are not fetched anew.
+type Committer interface {
+	// Commit records the items of those messages upserted.
+	Commit(upserted []client.Message)
+}
+
+// New returns the Source of a site per its declared type (Site.Source).
+func New(env *client.Env, site *mirror.Site) (Source, error) {
+	switch strings.ToLower(site.Source) {
+	case "", WordPress:
+		return wordpress.NewWordPress(env, site), nil
+	case Feed:
+		return feed.New(env, site), nil
+	case Ghost:
+		return ghost.New(env, site), nil
+	case JSONFeed:
+		return jsonfeed.New(env, site), nil
+	case Sitemap:
+		return sitemap.New(env, site), nil
+	case Markdown:
+		return markdown.New(env, site), nil
+	case ActivityPub:
+		return activitypub.New(env, site), nil
+	}
+	return nil, errors.Errorf("unknown source : %s", site.Source)
+}
+
+// Checkpoint retrieves the checkpoint of a site from cache;
+// the latest Message.DateUpdate upserted thereof. Zero if none.
+func Checkpoint(env *client.Env, site *mirror.Site) time.Time {
+	t := time.Time{}
+	bb := env.GetCache(CacheKeyCheckpointPrefix + site.UserHandle)
+	if len(bb) == 0 {
+		return t
+	}
+	t, _ = time.Parse(time.RFC3339, strings.TrimSpace(convert.BytesToString(bb)))
+	return t
+}
+
+// SetCheckpoint writes the checkpoint (t) of a site to cache.
+func SetCheckpoint(env *client.Env, site *mirror.Site, t time.Time) error {
+	return env.SetCache(CacheKeyCheckpointPrefix+site.UserHandle, t.UTC().Format(time.RFC3339))
+}
+
+// Advance returns the checkpoint of a site advanced from since to the latest message upserted (done),
+// yet short of the earliest of those failed (failed) and of those items skipped to be retried (see mirror.Skip),
+// so that the next run fetches the latter anew.
+func Advance(site *mirror.Site, since time.Time, done []client.Message, failed time.Time) time.Time {
+	for _, skip := range site.Skipped {
+		if !skip.Retry.IsZero() && (failed.IsZero() || skip.Retry.Before(failed)) {
+			failed = skip.Retry
+		}
+	}
+	latest := since
+	for _, msg := range done {
+		if msg.DateUpdate.After(latest) {
+			latest = msg.DateUpdate
+		}
+	}
+	if !failed.IsZero() && !latest.Before(failed) {
+		latest = failed.Add(-time.Second)
+	}
+	return latest
+}
+
+// Due reports whether the items of a site are due for upsert per its schedule (Site.Schedule);
+// always if it declares none, else if its last run (see SetLastRun) is at least that long ago.
+func Due(env *client.Env, site *mirror.Site, now time.Time) bool {
+	if site.Schedule <= 0 {
+		return true
+	}
+	bb := env.GetCache(CacheKeyLastRunPrefix + site.UserHandle)
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(convert.BytesToString(bb)))
+	if err != nil {
+		return true
+	}
+	return now.Sub(t) >= time.Duration(site.Schedule)
+}
+
+// SetLastRun writes the time (t) of the last run (upsert) of a site to cache.
+func SetLastRun(env *client.Env, site *mirror.Site, t time.Time) error {
+	return env.SetCache(CacheKeyLastRunPrefix+site.UserHandle, t.UTC().Format(time.RFC3339))
+}
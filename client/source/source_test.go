@@ -0,0 +1,123 @@
+package source
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
+)
+
+func TestAdvance(t *testing.T) {
+	var (
+		since = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		t1    = since.Add(time.Hour)
+		t2    = since.Add(2 * time.Hour)
+		t3    = since.Add(3 * time.Hour)
+	)
+	msgs := func(tt ...time.Time) []client.Message {
+		mm := []client.Message{}
+		for _, t := range tt {
+			mm = append(mm, client.Message{DateUpdate: t})
+		}
+		return mm
+	}
+	tests := []struct {
+		name    string
+		done    []client.Message
+		failed  time.Time
+		skipped []mirror.Skip
+		want    time.Time
+	}{
+		{"none", nil, time.Time{}, nil, since},
+		{"latest", msgs(t1, t3, t2), time.Time{}, nil, t3},
+		{"failed", msgs(t1, t3), t2, nil, t2.Add(-time.Second)},
+		{"failed after latest", msgs(t1), t3, nil, t1},
+		{"retry", msgs(t1, t3), time.Time{}, []mirror.Skip{{Reason: "fetch failed", Retry: t2}}, t2.Add(-time.Second)},
+		{"retry before failed", msgs(t3), t2, []mirror.Skip{{Retry: t1}}, t1.Add(-time.Second)},
+		{"not retried", msgs(t1, t3), time.Time{}, []mirror.Skip{{Reason: "not an article"}}, t3},
+	}
+	for _, tt := range tests {
+		site := &mirror.Site{Skipped: tt.skipped}
+		if got := Advance(site, since, tt.done, tt.failed); !got.Equal(tt.want) {
+			t.Errorf("%s : got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+const testArticle = `<html><head><title>%s</title><meta property="og:type" content="article"></head>
+<body><article><p>The body of the article, long enough to be regarded as content.</p></article></body></html>`
+
+// A page of a sitemap whose fetch fails is fetched anew per the next run, the checkpoint stopping short of it.
+func TestAdvanceRefetchesFailedPage(t *testing.T) {
+	var failing, hitsB int64 = 1, 0
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+<url><loc>%[1]s/a</loc><lastmod>2024-01-01T00:00:00Z</lastmod></url>
+<url><loc>%[1]s/b</loc><lastmod>2024-01-02T00:00:00Z</lastmod></url>
+<url><loc>%[1]s/c</loc><lastmod>2024-01-03T00:00:00Z</lastmod></url>
+</urlset>`, srv.URL)
+	})
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) { fmt.Fprintf(w, testArticle, "A") })
+	mux.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) { fmt.Fprintf(w, testArticle, "C") })
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&hitsB, 1)
+		if atomic.LoadInt64(&failing) == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, testArticle, "B")
+	})
+
+	env := &client.Env{
+		Logger: log.New(io.Discard, "", 0),
+		Cache:  t.TempDir(),
+		Client: client.Client{Timeout: 5 * time.Second},
+	}
+	run := func(since time.Time) (time.Time, []client.Message) {
+		site := &mirror.Site{
+			UserHandle: "test", HostURL: srv.URL, ChnID: "e2b3e5b4-1b0e-4c8e-8f1b-3c1b7e0e5a11",
+			Source: Sitemap, SitemapURL: srv.URL + "/sitemap.xml", RateLimit: mirror.Duration(time.Millisecond),
+		}
+		src, err := New(env, site)
+		if err != nil {
+			t.Fatal(err)
+		}
+		src.Fetch(since)
+		msgs := src.Messages()
+		return Advance(site, since, msgs, time.Time{}), msgs
+	}
+
+	checkpoint, msgs := run(time.Time{})
+	if len(msgs) != 2 {
+		t.Fatalf("run 1 : got %d messages, want 2", len(msgs))
+	}
+	if want := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC).Add(-time.Second); !checkpoint.Equal(want) {
+		t.Errorf("run 1 : checkpoint %v, want %v (short of the failed page)", checkpoint, want)
+	}
+
+	atomic.StoreInt64(&failing, 0)
+	checkpoint, msgs = run(checkpoint)
+	if n := atomic.LoadInt64(&hitsB); n != 2 {
+		t.Errorf("failed page fetched %d times, want 2", n)
+	}
+	titles := []string{}
+	for _, msg := range msgs {
+		titles = append(titles, msg.Title)
+	}
+	if len(msgs) != 2 || msgs[0].Title != "C" || msgs[1].Title != "B" {
+		t.Errorf("run 2 : got messages %v, want [C B]", titles)
+	}
+	if want := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC); !checkpoint.Equal(want) {
+		t.Errorf("run 2 : checkpoint %v, want %v", checkpoint, want)
+	}
+}
@@ -8,6 +8,7 @@ import (
 // UpsertMsgByTkn performs a POST request to Uqrate's API service endpoint
 // for upserting a long-form Message (msg) of an externally-hosted
 // Channel.Slug (slug) using bearer-token (token) authorization.
+// A reply (msg.ParentID) thereto requires no title.
 //
 //	Defaults:
 //		token (args[0]): env.GetCache(client.CacheKeyTknPrefix + env.Client.User)
@@ -47,7 +48,7 @@ func (env *Env) UpsertMsgByTkn(msg *Message, args ...string) *Response {
 	if msg.ID == "" {
 		rtn.Error = "missing message id"
 	}
-	if msg.Title == "" {
+	if msg.Title == "" && msg.ParentID == "" {
 		rtn.Error = "missing message title"
 	}
 	if msg.Body == "" {
@@ -110,7 +111,7 @@ func (env *Env) UpsertMsgByKey(msg *Message, key string) *Response {
 	if msg.ID == "" {
 		rtn.Error = "missing message id"
 	}
-	if msg.Title == "" {
+	if msg.Title == "" && msg.ParentID == "" {
 		rtn.Error = "missing message title"
 	}
 	if msg.Body == "" {
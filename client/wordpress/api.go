@@ -0,0 +1,190 @@
+package wordpress
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// RestRouteRoot is that of the API of a site of the query-string (rest_route) mode; see mirror.APIRestRoute.
+const RestRouteRoot = "/?rest_route=/"
+
+// RelAPI is the link relation advertising the root of a site's WordPress REST API.
+// https://developer.wordpress.org/rest-api/using-the-rest-api/discovery/
+const RelAPI = "https://api.w.org/"
+
+// WordPress.com REST proxy of the sites it hosts.
+// https://developer.wordpress.com/docs/api/
+const (
+	WPCOMHost    = "public-api.wordpress.com"
+	WPCOMBaseURL = "https://" + WPCOMHost
+	WPCOMSiteURI = "/rest/v1.1/sites/%s?fields=name,description,URL,icon,options"
+)
+
+// apiURL returns the URL of a WordPress API endpoint (uri) per API mode of the site.
+//
+//	uri : "/wp-json/wp/v2/posts?_fields=id"
+//	rtn : "https://TheWpSite.com/wp-json/wp/v2/posts?_fields=id"                          (wpjson)
+//	rtn : "https://public-api.wordpress.com/wp/v2/sites/TheWpSite.com/posts?_fields=id"   (wpcom)
+//	rtn : "https://TheWpSite.com/?rest_route=/wp/v2/posts&_fields=id"                     (rest_route)
+//
+// A discovered root (Site.APIRoot) replaces that of <HostURL>/wp-json/ else <HostURL>/?rest_route=/ .
+func (wp WP) apiURL(uri string) string {
+	root := wp.Site.APIRoot
+	switch wp.Site.API {
+	case mirror.APIRestRoute:
+		if root == "" || !strings.Contains(root, "rest_route=") {
+			root = strings.TrimSuffix(wp.Site.HostURL, "/") + RestRouteRoot
+		}
+		route := strings.SplitN(strings.TrimPrefix(uri, "/wp-json/"), "?", 2)
+		if len(route) > 1 && route[1] != "" {
+			return root + route[0] + "&" + route[1]
+		}
+		return root + route[0]
+	case mirror.APIWPCOM:
+		domain := fqdn(wp.Site.HostURL)
+		if strings.HasPrefix(uri, "/wp-json/wp/v2/") {
+			return WPCOMBaseURL + "/wp/v2/sites/" + domain + "/" + strings.TrimPrefix(uri, "/wp-json/wp/v2/")
+		}
+		if uri == SiteURI {
+			return WPCOMBaseURL + strings.Replace(WPCOMSiteURI, "%s", domain, 1)
+		}
+		return WPCOMBaseURL + "/wp/v2/sites/" + domain + strings.TrimPrefix(uri, "/wp-json")
+	}
+	if root != "" {
+		return strings.TrimSuffix(root, "/") + "/" + strings.TrimPrefix(uri, "/wp-json/")
+	}
+	return wp.Site.HostURL + uri
+}
+
+// CacheKey returns the cache key of a WordPress API endpoint (uri) of the site;
+// the same regardless of its API mode or root (see routeToFname).
+func (wp WP) CacheKey(uri string) string {
+	return routeToFname(fqdn(wp.Site.HostURL), uri)
+}
+
+// PostsCacheKeys returns the cache key of posts of each (post) type of the site.
+func (wp WP) PostsCacheKeys() []string {
+	keys := []string{}
+	for _, base := range wp.RestBases() {
+		keys = append(keys, wp.CacheKey(postsURI(base)))
+	}
+	return keys
+}
+
+// discoverAPIRoot sets the API root (Site.APIRoot) of a self-hosted site per that advertised at its home page;
+// in its Link header, else in its <link rel="https://api.w.org/"> tag.
+func (wp WP) discoverAPIRoot() {
+	rsp := wp.Env.Get(wp.Site.HostURL, client.HTML)
+	wp.Site.Pause()
+	if rsp.Error != "" {
+		return
+	}
+	root := linkHeaderHref(rsp.Header.Values("Link"), RelAPI)
+	if root == "" {
+		root = linkTagHref(rsp.Body, RelAPI)
+	}
+	if root == "" {
+		return
+	}
+	wp.Site.APIRoot = root
+	if strings.Contains(root, "rest_route=") {
+		wp.Site.API = mirror.APIRestRoute
+	}
+}
+
+// linkHeaderHref returns the URL of the link relation (rel) of a list of Link header values, e.g.,
+//
+//	`<https://TheWpSite.com/wp-json/>; rel="https://api.w.org/"`
+func linkHeaderHref(values []string, rel string) string {
+	for _, v := range values {
+		for _, link := range strings.Split(v, ",") {
+			parts := strings.Split(link, ";")
+			href := strings.Trim(strings.TrimSpace(parts[0]), "<>")
+			for _, p := range parts[1:] {
+				kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+				if len(kv) == 2 && strings.EqualFold(kv[0], "rel") && strings.Trim(kv[1], `"`) == rel {
+					return href
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// linkTagHref returns the href of the first <link> tag of the link relation (rel) in an HTML document.
+func linkTagHref(doc, rel string) string {
+	z := html.NewTokenizer(strings.NewReader(doc))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			t := z.Token()
+			if t.DataAtom == atom.Body {
+				return ""
+			}
+			if t.DataAtom != atom.Link {
+				continue
+			}
+			var href, got string
+			for _, a := range t.Attr {
+				switch a.Key {
+				case "rel":
+					got = a.Val
+				case "href":
+					href = a.Val
+				}
+			}
+			if got == rel && href != "" {
+				return href
+			}
+		}
+	}
+}
+
+// isJSON reports whether a response body is (presumably) JSON, versus an HTML page served in lieu.
+func isJSON(body string) bool {
+	body = strings.TrimSpace(body)
+	return strings.HasPrefix(body, "{") || strings.HasPrefix(body, "[")
+}
+
+// isWPCOM reports whether the site is hosted at WordPress.com per its domain.
+func (wp WP) isWPCOM() bool {
+	return strings.HasSuffix(strings.ToLower(fqdn(wp.Site.HostURL)), ".wordpress.com")
+}
+
+// wpcomSite contains the subset of a WordPress.com site record
+// corresponding to that of the /wp-json endpoint (SiteURI) of self-hosted sites.
+type wpcomSite struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"URL,omitempty"`
+	Icon        struct {
+		Img string `json:"img,omitempty"`
+	} `json:"icon,omitempty"`
+	Options struct {
+		GMTOffset mirror.Offset `json:"gmt_offset,omitempty"`
+		Timezone  string        `json:"timezone,omitempty"`
+	} `json:"options,omitempty"`
+}
+
+// unmarshalWPCOMSite merges a WordPress.com site record (j) into the site.
+func (wp WP) unmarshalWPCOMSite(j string) error {
+	s := wpcomSite{}
+	if err := json.Unmarshal([]byte(j), &s); err != nil {
+		return err
+	}
+	wp.Site.Name = s.Name
+	wp.Site.Description = s.Description
+	wp.Site.URL = s.URL
+	wp.Site.Home = s.URL
+	wp.Site.Icon = s.Icon.Img
+	wp.Site.GMTOffset = s.Options.GMTOffset
+	wp.Site.TimezoneString = s.Options.Timezone
+	return nil
+}
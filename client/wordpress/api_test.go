@@ -0,0 +1,124 @@
+package wordpress
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sempernow/uqc/client/mirror"
+)
+
+func TestAPIURLAndCacheKey(t *testing.T) {
+	const host = "https://TheWpSite.com"
+	sites := map[string]mirror.Site{
+		"wpjson":      {HostURL: host},
+		"rest_route":  {HostURL: host, API: mirror.APIRestRoute},
+		"wpcom":       {HostURL: host, API: mirror.APIWPCOM},
+		"custom root": {HostURL: host, APIRoot: "https://TheWpSite.com/api/"},
+	}
+	tests := []struct {
+		site string
+		uri  string
+		url  string
+		key  string
+	}{
+		{"wpjson", "/wp-json/wp/v2/posts?_fields=id", host + "/wp-json/wp/v2/posts?_fields=id", "TheWpSite.com_posts.json"},
+		{"wpjson", "/wp-json/wp/v2/users/7", host + "/wp-json/wp/v2/users/7", "TheWpSite.com_users.7.json"},
+		{"wpjson", SiteURI, host + SiteURI, "TheWpSite.com.json"},
+		{"wpjson", CommentsURI + "&post=12", host + CommentsURI + "&post=12", "TheWpSite.com_comments.post.12.json"},
+
+		{"rest_route", "/wp-json/wp/v2/posts?_fields=id", host + "/?rest_route=/wp/v2/posts&_fields=id", "TheWpSite.com_posts.json"},
+		{"rest_route", "/wp-json/wp/v2/users/7", host + "/?rest_route=/wp/v2/users/7", "TheWpSite.com_users.7.json"},
+		{"rest_route", "/wp-json/wp/v2/tags?per_page=100", host + "/?rest_route=/wp/v2/tags&per_page=100", "TheWpSite.com_tags.json"},
+
+		{"wpcom", "/wp-json/wp/v2/posts?_fields=id", WPCOMBaseURL + "/wp/v2/sites/TheWpSite.com/posts?_fields=id", "TheWpSite.com_posts.json"},
+		{"wpcom", "/wp-json/wp/v2/users/7", WPCOMBaseURL + "/wp/v2/sites/TheWpSite.com/users/7", "TheWpSite.com_users.7.json"},
+		{"wpcom", SiteURI, WPCOMBaseURL + "/rest/v1.1/sites/TheWpSite.com?fields=name,description,URL,icon,options", "TheWpSite.com.json"},
+
+		{"custom root", "/wp-json/wp/v2/posts?_fields=id", host + "/api/wp/v2/posts?_fields=id", "TheWpSite.com_posts.json"},
+		{"custom root", "/wp-json/wp/v2/users/7", host + "/api/wp/v2/users/7", "TheWpSite.com_users.7.json"},
+		{"custom root", "/wp-json/wp/v2/tags?per_page=100", host + "/api/wp/v2/tags?per_page=100", "TheWpSite.com_tags.json"},
+		{"custom root", SiteURI, host + "/api/?_fields=name,description,url,home,gmt_offset,timezone_string,site_icon_url", "TheWpSite.com.json"},
+	}
+	for _, tt := range tests {
+		site := sites[tt.site]
+		wp := WP{Adapter: mirror.Adapter{Site: &site}}
+		if got := wp.apiURL(tt.uri); got != tt.url {
+			t.Errorf("%s : apiURL(%q)\n got: %s\nwant: %s", tt.site, tt.uri, got, tt.url)
+		}
+		if got := wp.CacheKey(tt.uri); got != tt.key {
+			t.Errorf("%s : CacheKey(%q) = %s, want %s", tt.site, tt.uri, got, tt.key)
+		}
+	}
+}
+
+func TestCacheKeysDistinct(t *testing.T) {
+	uris := []string{SiteURI, postsURI("posts"), postsURI("pages"), TagsURI, CatsURI, TypesURI, "/wp-json/wp/v2/users/7"}
+	for _, site := range []mirror.Site{
+		{HostURL: "https://x.com"},
+		{HostURL: "https://x.com", API: mirror.APIRestRoute},
+		{HostURL: "https://x.com", API: mirror.APIWPCOM},
+		{HostURL: "https://x.com", APIRoot: "https://x.com/api/"},
+	} {
+		site := site
+		wp := WP{Adapter: mirror.Adapter{Site: &site}}
+		seen := map[string]string{}
+		for _, uri := range uris {
+			key := wp.CacheKey(uri)
+			if prev, ok := seen[key]; ok {
+				t.Errorf("%+v : CacheKey of %q and %q both %s", site, prev, uri, key)
+			}
+			seen[key] = uri
+		}
+	}
+}
+
+func TestAPIHost(t *testing.T) {
+	tests := []struct {
+		site mirror.Site
+		want string
+	}{
+		{mirror.Site{HostURL: "https://www.Foo.com"}, "foo.com"},
+		{mirror.Site{HostURL: "https://foo.wordpress.com"}, WPCOMHost},
+		{mirror.Site{HostURL: "https://foo.com", API: mirror.APIWPCOM}, WPCOMHost},
+		{mirror.Site{HostURL: "https://foo.com", API: mirror.APIRestRoute}, "foo.com"},
+		{mirror.Site{HostURL: "https://foo.com", APIRoot: "https://api.foo.net/wp-json/"}, "api.foo.net"},
+		{mirror.Site{HostURL: "https://foo.wordpress.com", Source: "feed"}, "foo.wordpress.com"},
+		{mirror.Site{}, ""},
+	}
+	for _, tt := range tests {
+		if got := apiHost(tt.site); got != tt.want {
+			t.Errorf("apiHost(%+v) = %q, want %q", tt.site, got, tt.want)
+		}
+	}
+}
+
+func TestSiteGotIcon(t *testing.T) {
+	wp := testWP(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wp-json/" {
+			http.NotFound(w, r)
+			return
+		}
+		if !strings.Contains(r.URL.Query().Get("_fields"), "site_icon_url") {
+			t.Errorf("site request sans site_icon_url : %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"name":"Site","description":"About","url":"https://x.com","site_icon_url":"https://x.com/icon.png"}`)
+	})
+	wp.Site.APIRoot = wp.Site.HostURL + "/wp-json/"
+	wp.SiteGot()
+	if wp.Site.Error != "" {
+		t.Fatalf("SiteGot : %s", wp.Site.Error)
+	}
+	if wp.Site.Name != "Site" || wp.Site.Icon != "https://x.com/icon.png" {
+		t.Errorf("site : got name %q, icon %q", wp.Site.Name, wp.Site.Icon)
+	}
+
+	if err := wp.unmarshalWPCOMSite(`{"name":"Com","URL":"https://c.com","icon":{"img":"https://c.com/i.png","ico":"https://c.com/i.ico"}}`); err != nil {
+		t.Fatal(err)
+	}
+	if wp.Site.Icon != "https://c.com/i.png" {
+		t.Errorf("wpcom icon : got %q", wp.Site.Icon)
+	}
+}
@@ -0,0 +1,115 @@
+package wordpress
+
+import (
+	"encoding/json"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sempernow/kit/id"
+	"github.com/sempernow/kit/types/convert"
+	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
+)
+
+// Comment contains a subset of keys from its WordPress
+// REST API namesake of the Comments endpoint.
+// https://developer.wordpress.org/rest-api/reference/comments/
+type Comment struct {
+	ID         int      `json:"id,omitempty"`
+	Post       int      `json:"post,omitempty"`
+	Parent     int      `json:"parent,omitempty"` // 0 @ reply to post
+	AuthorName string   `json:"author_name,omitempty"`
+	Date       string   `json:"date,omitempty"`
+	DateGMT    string   `json:"date_gmt,omitempty"`
+	Content    Rendered `json:"content,omitempty"`
+	Link       string   `json:"link,omitempty"`
+}
+
+// commentID returns the (static) Message.ID of a comment (cid) under its mirrored post (root);
+// a UUID (v5) per root Message.ID namespace.
+func commentID(root string, cid int) string {
+	u, _ := id.UUIDv5(root, "comment/"+convert.IntToString(cid))
+	return u
+}
+
+// PostComments retrieves the approved comments of a post, in order of their (ascending) ID,
+// which is that of replies following their parent; all pages thereof (see pages), cached per post.
+func (wp WP) PostComments(post *Post) []Comment {
+	cc := []Comment{}
+	uri := CommentsURI + "&post=" + convert.IntToString(post.ID)
+	key := wp.CacheKey(uri)
+	if bb := wp.Env.GetCache(key); len(bb) > 0 {
+		if err := json.Unmarshal(bb, &cc); err != nil {
+			log.Printf("ERR : Unmarshalling : %s\n", err.Error())
+		}
+	} else {
+		err := wp.pages(uri, func(body string) (int, error) {
+			page := []Comment{}
+			if err := json.Unmarshal([]byte(body), &page); err != nil {
+				return 0, err
+			}
+			cc = append(cc, page...)
+			return len(page), nil
+		})
+		if err != nil {
+			log.Printf("ERR : comments of post %d @ %s : %s\n", post.ID, wp.Site.HostURL, err.Error())
+		} else if len(cc) > 0 {
+			wp.Env.SetCache(key, convert.Stringify(cc)) // Not of some pages, lest the rest are never fetched.
+		}
+	}
+	sort.Slice(cc, func(i, k int) bool { return cc[i].ID < cc[k].ID })
+	return cc
+}
+
+// CommentsToMsgs denormalizes the comments of a post into replies (client.Message)
+// under its mirrored message (root), preserving their nesting.
+func (wp WP) CommentsToMsgs(root *client.Message, post *Post) []client.Message {
+	list := []client.Message{}
+	if root.ID == "" {
+		return list
+	}
+	for _, c := range wp.PostComments(post) {
+		if c.Post != 0 && c.Post != post.ID {
+			continue
+		}
+		msg := client.Message{
+			ID:       commentID(root.ID, c.ID),
+			ChnID:    root.ChnID,
+			ThreadID: root.ID,
+			ParentID: root.ID,
+			Author:   strings.TrimSpace(c.AuthorName),
+			Body:     c.Content.Rendered,
+		}
+		if msg.ID == "" {
+			continue
+		}
+		if c.Parent != 0 {
+			msg.ParentID = commentID(root.ID, c.Parent)
+		}
+		if wp.Cleaner != nil {
+			msg.Body = wp.Cleaner(msg.Body)
+		}
+		if uri, err := mirror.LinkToURI(c.Link, wp.Site.HostURL); err == nil {
+			msg.URI = uri
+		}
+		msg.DateUpdate = ToRFC3339(c.DateGMT, 0)
+		if mirror.IsZero(msg.DateUpdate) {
+			msg.DateUpdate = ToRFC3339In(c.Date, wp.Site.Location())
+		}
+		list = append(list, msg)
+	}
+	return list
+}
+
+// CommentsCacheKeys returns the cache keys of comments of all posts of the site.
+func (wp WP) CommentsCacheKeys() []string {
+	keys := []string{}
+	pattern := strings.TrimSuffix(wp.CacheKey(CommentsURI), ".json") + ".post.*.json"
+	matches, _ := filepath.Glob(filepath.Join(wp.Env.Cache, pattern))
+	for _, m := range matches {
+		keys = append(keys, filepath.Base(m))
+	}
+	return keys
+}
@@ -0,0 +1,73 @@
+package wordpress
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// commentsHandler serves n comments of post 12 in pages (of per_page); declaring X-WP-TotalPages if totals.
+func commentsHandler(n int, totals bool, requests *int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(requests, 1)
+		q := r.URL.Query()
+		if r.URL.Path != "/wp-json/wp/v2/comments" || q.Get("post") != "12" || q.Get("status") != "approve" {
+			http.NotFound(w, r)
+			return
+		}
+		per, _ := strconv.Atoi(q.Get("per_page"))
+		page, _ := strconv.Atoi(q.Get("page"))
+		pages := (n + per - 1) / per
+		if page > pages {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":"rest_comment_invalid_page_number"}`))
+			return
+		}
+		cc := []Comment{}
+		for id := (page-1)*per + 1; id <= n && id <= page*per; id++ {
+			cc = append(cc, Comment{ID: n + 1 - id, Post: 12}) // Descending, per WordPress
+		}
+		if totals {
+			w.Header().Set("X-WP-TotalPages", strconv.Itoa(pages))
+		}
+		json.NewEncoder(w).Encode(cc)
+	}
+}
+
+func TestPostComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int
+		totals   bool
+		requests int64
+	}{
+		{"pages per total", 2*PerPage + 5, true, 3},
+		{"pages sans total", 2*PerPage + 5, false, 3},
+		{"full last page sans total", 2 * PerPage, false, 3},
+		{"full last page", 2 * PerPage, true, 2},
+		{"one page", 3, true, 1},
+	}
+	for _, tt := range tests {
+		var requests int64
+		wp := testWP(t, commentsHandler(tt.n, tt.totals, &requests))
+		cc := wp.PostComments(&Post{ID: 12})
+		if len(cc) != tt.n {
+			t.Errorf("%s : %d comments, want %d", tt.name, len(cc), tt.n)
+			continue
+		}
+		for i, c := range cc {
+			if c.ID != i+1 {
+				t.Errorf("%s : comment %d : ID %d, want ascending", tt.name, i, c.ID)
+				break
+			}
+		}
+		if requests != tt.requests {
+			t.Errorf("%s : %d requests, want %d", tt.name, requests, tt.requests)
+		}
+		if cc := wp.PostComments(&Post{ID: 12}); len(cc) != tt.n || requests != tt.requests {
+			t.Errorf("%s : cached : %d comments, %d requests", tt.name, len(cc), requests)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package wordpress
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sempernow/uqc/client/mirror"
+)
+
+// Change is that of a field of a site between two sites lists.
+type Change struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// SiteDiff is that of a site between two sites lists; added, removed, else its changed fields.
+// Flipped reports whether its Status.Code or Error changed, e.g., of a site that died or moved.
+type SiteDiff struct {
+	Handle  string   `json:"handle"`
+	Added   bool     `json:"added,omitempty"`
+	Removed bool     `json:"removed,omitempty"`
+	Flipped bool     `json:"flipped,omitempty"`
+	Changes []Change `json:"changes,omitempty"`
+}
+
+// DiffSitesList compares two sites lists (old and cur) field by field; sites matched per ChnID, else handle.
+// Returns the diff of each site added, removed or changed; in the order of cur, then those removed in that of old.
+func DiffSitesList(old, cur []mirror.Site) []SiteDiff {
+	diffs := []SiteDiff{}
+	matched := map[int]bool{}
+	for _, n := range cur {
+		i := matchSite(old, n)
+		if i < 0 {
+			diffs = append(diffs, SiteDiff{Handle: n.UserHandle, Added: true})
+			continue
+		}
+		matched[i] = true
+		if d := diffSite(old[i], n); len(d.Changes) > 0 {
+			diffs = append(diffs, d)
+		}
+	}
+	for i, o := range old {
+		if !matched[i] {
+			diffs = append(diffs, SiteDiff{Handle: o.UserHandle, Removed: true})
+		}
+	}
+	return diffs
+}
+
+// matchSite returns the index of the site (s) in sites, per ChnID, else handle; -1 if none.
+func matchSite(sites []mirror.Site, s mirror.Site) int {
+	for i, site := range sites {
+		if s.ChnID != "" && strings.EqualFold(site.ChnID, s.ChnID) {
+			return i
+		}
+	}
+	for i, site := range sites {
+		if site.ChnID == "" && strings.EqualFold(site.UserHandle, s.UserHandle) {
+			return i
+		}
+	}
+	return -1
+}
+
+// diffSite compares the fields of a site; those of its record (see Record), then its dynamic fields.
+func diffSite(old, cur mirror.Site) SiteDiff {
+	d := SiteDiff{Handle: cur.UserHandle}
+	cmp := func(field, o, n string) {
+		if o != n {
+			d.Changes = append(d.Changes, Change{Field: field, Old: o, New: n})
+		}
+	}
+	oo := map[string]string{}
+	for _, kv := range old.Record() {
+		oo[kv[0]] = kv[1]
+	}
+	seen := map[string]bool{}
+	for _, kv := range cur.Record() {
+		cmp(kv[0], oo[kv[0]], kv[1])
+		seen[kv[0]] = true
+	}
+	for _, kv := range old.Record() {
+		if !seen[kv[0]] {
+			cmp(kv[0], kv[1], "")
+		}
+	}
+	cmp("name", old.Name, cur.Name)
+	cmp("description", old.Description, cur.Description)
+	cmp("url", old.URL, cur.URL)
+	cmp("home", old.Home, cur.Home)
+	cmp("gmt_offset", fmt.Sprint(old.GMTOffset), fmt.Sprint(cur.GMTOffset))
+	cmp("timezone_string", old.TimezoneString, cur.TimezoneString)
+	cmp("site_icon_url", old.Icon, cur.Icon)
+	cmp("status.object", old.Status.Object, cur.Status.Object)
+
+	n := len(d.Changes)
+	cmp("status.code", fmt.Sprint(old.Status.Code), fmt.Sprint(cur.Status.Code))
+	cmp("error", old.Error, cur.Error)
+	d.Flipped = len(d.Changes) > n
+	return d
+}
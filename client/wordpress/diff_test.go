@@ -0,0 +1,53 @@
+package wordpress
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sempernow/uqc/client/mirror"
+)
+
+func TestDiffSitesList(t *testing.T) {
+	old := []mirror.Site{
+		{UserHandle: "same", ChnID: "c1", HostURL: "https://same.x", Name: "Same"},
+		{UserHandle: "renamed", ChnID: "c2", HostURL: "https://r.x"},
+		{UserHandle: "gone", ChnID: "c3"},
+		{UserHandle: "sansid", HostURL: "https://s.x", Source: "feed"},
+		{UserHandle: "died", ChnID: "c5", Status: mirror.Status{Code: 200}},
+	}
+	cur := []mirror.Site{
+		{UserHandle: "new", ChnID: "c6"},
+		{UserHandle: "Renamed2", ChnID: "C2", HostURL: "https://r.x", Name: "R", GMTOffset: 5.5},
+		{UserHandle: "died", ChnID: "c5", Status: mirror.Status{Code: 404}, Error: "HTTP 404"},
+		{UserHandle: "SansID", HostURL: "https://s.x"},
+		{UserHandle: "same", ChnID: "c1", HostURL: "https://same.x", Name: "Same"},
+	}
+	want := []SiteDiff{
+		{Handle: "new", Added: true},
+		{Handle: "Renamed2", Changes: []Change{
+			{Field: "user_handle", Old: "renamed", New: "Renamed2"},
+			{Field: "chn_id", Old: "c2", New: "C2"},
+			{Field: "name", New: "R"},
+			{Field: "gmt_offset", Old: "0", New: "5.5"},
+		}},
+		{Handle: "died", Flipped: true, Changes: []Change{
+			{Field: "status.code", Old: "200", New: "404"},
+			{Field: "error", New: "HTTP 404"},
+		}},
+		{Handle: "SansID", Changes: []Change{
+			{Field: "user_handle", Old: "sansid", New: "SansID"},
+			{Field: "source", Old: "feed"},
+		}},
+		{Handle: "gone", Removed: true},
+	}
+	got := DiffSitesList(old, cur)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffSitesList\n got: %+v\nwant: %+v", got, want)
+	}
+	if d := DiffSitesList(old, old); len(d) != 0 {
+		t.Errorf("DiffSitesList of same : %+v", d)
+	}
+	if d := DiffSitesList(nil, nil); d == nil || len(d) != 0 {
+		t.Errorf("DiffSitesList of none : %#v", d)
+	}
+}
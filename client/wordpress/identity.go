@@ -0,0 +1,137 @@
+package wordpress
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sempernow/kit/types/convert"
+	"github.com/sempernow/uqc/client/mirror"
+)
+
+// SuffixAliases is that of the file name of a site's alias table.
+const SuffixAliases = "_ids.json"
+
+// Aliases is the alias table of a site; the Message.ID of each post mirrored (under the legacy URI identity)
+// before the site declared its stable identity, keyed by that identity (see identityKey).
+type Aliases map[string]string
+
+// identityKey returns the name of the UUID (v5) of a post's message per identity strategy of the site.
+func (wp WP) identityKey(post *Post, uri string) string {
+	switch wp.Site.Identity {
+	case mirror.IdentityID:
+		if post.ID != 0 {
+			return "wp:post:" + convert.IntToString(post.ID)
+		}
+	case mirror.IdentityGUID:
+		if post.GUID.Rendered != "" {
+			return post.GUID.Rendered
+		}
+	}
+	return uri
+}
+
+// msgID returns the Message.ID of a post; that of its alias if any, else per identity strategy of the site.
+func (wp WP) msgID(post *Post, uri string) string {
+	key := wp.identityKey(post, uri)
+	if alias, ok := wp.Aliases[key]; ok && key != uri {
+		return alias
+	}
+	return mirror.MessageID(wp.Site.ChnID, key)
+}
+
+// aliasesFname returns the file name of the alias table of the site.
+func (wp WP) aliasesFname() string {
+	return fqdn(wp.Site.HostURL) + SuffixAliases
+}
+
+// aliasesPath returns the path of the alias table of the site; at assets, not cache,
+// since the table is not recoverable once the site's messages are mirrored under its declared identity.
+func (wp WP) aliasesPath() string {
+	return filepath.Join(wp.Env.Assets, wp.aliasesFname())
+}
+
+// LoadAliases reads the alias table of the site from assets; else from cache, where prior versions kept it.
+func (wp WP) LoadAliases() Aliases {
+	aa := Aliases{}
+	bb, err := os.ReadFile(wp.aliasesPath())
+	if err != nil {
+		bb = wp.Env.GetCache(wp.aliasesFname())
+	}
+	if len(bb) == 0 {
+		return aa
+	}
+	if err := json.Unmarshal(bb, &aa); err != nil {
+		log.Printf("ERR : Unmarshalling : %s\n", err.Error())
+	}
+	return aa
+}
+
+// SaveAliases writes the alias table of the site to assets.
+func (wp WP) SaveAliases() error {
+	return os.WriteFile(wp.aliasesPath(), []byte(convert.Stringify(wp.Aliases)), 0664)
+}
+
+// MigrateIDs reconciles the messages mirrored of the site's (currently fetched; see AllPosts) posts
+// under the legacy URI identity to its declared identity strategy,
+// by recording the legacy Message.ID of each as its alias. Returns the count of aliases added.
+func (wp WP) MigrateIDs() int {
+	n := 0
+	for _, post := range wp.Posts {
+		if wp.alias(&post) {
+			n++
+		}
+	}
+	return n
+}
+
+// aliasMirrored records the alias of each fetched post mirrored prior under the legacy URI identity,
+// as is that published not after the checkpoint (since), so that it is not mirrored anew (duplicated)
+// under the site's declared identity strategy if the site declared that sans MigrateIDs.
+// Returns the count of aliases added.
+func (wp WP) aliasMirrored(since time.Time) int {
+	n := 0
+	if since.IsZero() {
+		return n
+	}
+	for _, post := range wp.Posts {
+		t := ToRFC3339(post.DateGMT, 0)
+		if mirror.IsZero(t) {
+			t = ToRFC3339In(post.Date, wp.Site.Location())
+		}
+		if t.After(since) {
+			continue
+		}
+		if wp.alias(&post) {
+			n++
+		}
+	}
+	return n
+}
+
+// alias records the legacy Message.ID of a post as its alias, unless it has such,
+// or the site declares the legacy (URI) identity. Reports whether added.
+func (wp WP) alias(post *Post) bool {
+	if wp.Site.Identity == "" || wp.Site.Identity == mirror.IdentityURI {
+		return false
+	}
+	uri, err := mirror.LinkToURI(post.Link, wp.Site.HostURL)
+	if err != nil {
+		return false
+	}
+	key := wp.identityKey(post, uri)
+	if key == uri {
+		return false
+	}
+	if _, ok := wp.Aliases[key]; ok {
+		return false
+	}
+	legacy := mirror.MessageID(wp.Site.ChnID, uri)
+	if legacy == "" {
+		return false
+	}
+	wp.Aliases[key] = legacy
+	return true
+}
@@ -0,0 +1,127 @@
+package wordpress
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
+)
+
+const testChnID = "d5750f33-a12d-4719-9600-94fcee80f487"
+
+func TestIdentityKey(t *testing.T) {
+	post := Post{ID: 29343, GUID: Rendered{Rendered: "https://x.com/?p=29343"}}
+	tests := []struct {
+		identity, want string
+	}{
+		{"", "x.com/a-post"},
+		{mirror.IdentityURI, "x.com/a-post"},
+		{mirror.IdentityID, "wp:post:29343"},
+		{mirror.IdentityGUID, "https://x.com/?p=29343"},
+	}
+	for _, tt := range tests {
+		wp := WP{Adapter: mirror.Adapter{Site: &mirror.Site{Identity: tt.identity}}}
+		if got := wp.identityKey(&post, "x.com/a-post"); got != tt.want {
+			t.Errorf("%q : identityKey = %s, want %s", tt.identity, got, tt.want)
+		}
+	}
+	wp := WP{Adapter: mirror.Adapter{Site: &mirror.Site{Identity: mirror.IdentityGUID}}}
+	if got := wp.identityKey(&Post{ID: 1}, "x.com/a-post"); got != "x.com/a-post" {
+		t.Errorf("sans guid : identityKey = %s, want fallback to URI", got)
+	}
+}
+
+func TestMigrateIDsAndAliases(t *testing.T) {
+	env := &client.Env{Assets: t.TempDir(), Cache: t.TempDir()}
+	site := mirror.Site{HostURL: "https://x.com", ChnID: testChnID, Identity: mirror.IdentityID}
+	wp := WP{Adapter: mirror.Adapter{Env: env, Site: &site}, Aliases: Aliases{}}
+	wp.Posts = []Post{{ID: 7, Link: "https://x.com/a-post/"}, {ID: 8, Link: "https://x.com/b-post/"}}
+
+	if n := wp.MigrateIDs(); n != 2 {
+		t.Fatalf("MigrateIDs = %d, want 2", n)
+	}
+	if n := wp.MigrateIDs(); n != 0 {
+		t.Errorf("MigrateIDs anew = %d, want 0", n)
+	}
+	uri, _ := mirror.LinkToURI(wp.Posts[0].Link, site.HostURL)
+	legacy := mirror.MessageID(site.ChnID, uri)
+	if got := wp.msgID(&wp.Posts[0], uri); got != legacy {
+		t.Errorf("msgID = %s, want alias %s", got, legacy)
+	}
+	if got := wp.msgID(&Post{ID: 9}, "x.com/c-post"); got != mirror.MessageID(site.ChnID, "wp:post:9") {
+		t.Errorf("msgID sans alias = %s", got)
+	}
+
+	if err := wp.SaveAliases(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(env.Assets, "x.com"+SuffixAliases)); err != nil {
+		t.Errorf("alias table not at assets : %s", err)
+	}
+	if err := os.RemoveAll(env.Cache); err != nil {
+		t.Fatal(err)
+	}
+	if got := wp.LoadAliases(); len(got) != 2 || got["wp:post:7"] != legacy {
+		t.Errorf("LoadAliases sans cache = %v", got)
+	}
+}
+
+func TestFetchAliasesMirrored(t *testing.T) {
+	const posts = `[
+		{"id": 7, "status": "publish", "link": "LINK/a-post/", "date_gmt": "2022-01-01T10:00:00", "modified_gmt": "2022-07-01T10:00:00", "title": {"rendered": "A"}, "content": {"rendered": "<p>A</p>"}},
+		{"id": 8, "status": "publish", "link": "LINK/b-post/", "date_gmt": "2022-06-15T10:00:00", "modified_gmt": "2022-06-15T10:00:00", "title": {"rendered": "B"}, "content": {"rendered": "<p>B</p>"}}
+	]`
+	wp := testWP(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wp-json/wp/v2/posts" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(strings.ReplaceAll(posts, "LINK", "http://"+r.Host)))
+	})
+	env, site := wp.Env, wp.Site
+	env.Assets = t.TempDir()
+	since := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC) // Checkpoint of the site mirrored under the legacy identity
+
+	// The site declares its identity sans MigrateIDs; post 7 was mirrored, post 8 was not.
+	site.Identity = mirror.IdentityID
+	ids := func() map[int]string {
+		wp := NewWordPress(env, site)
+		wp.Fetch(since)
+		ids := map[int]string{}
+		for _, msg := range wp.Messages() {
+			for _, post := range wp.Posts {
+				if uri, _ := mirror.LinkToURI(post.Link, site.HostURL); uri == msg.URI {
+					ids[post.ID] = msg.ID
+				}
+			}
+		}
+		return ids
+	}
+	got := ids()
+	uri, _ := mirror.LinkToURI(site.HostURL+"/a-post/", site.HostURL)
+	if want := mirror.MessageID(site.ChnID, uri); got[7] != want {
+		t.Errorf("post mirrored prior : id %s, want legacy %s (else duplicated)", got[7], want)
+	}
+	if want := mirror.MessageID(site.ChnID, "wp:post:8"); got[8] != want {
+		t.Errorf("post not mirrored prior : id %s, want %s", got[8], want)
+	}
+	if _, err := os.Stat(filepath.Join(env.Assets, fqdn(site.HostURL)+SuffixAliases)); err != nil {
+		t.Errorf("aliases not saved : %s", err)
+	}
+	if again := ids(); again[7] != got[7] || again[8] != got[8] {
+		t.Errorf("ids of next run : %v, want %v", again, got)
+	}
+
+	// Of no checkpoint, the site was not mirrored prior.
+	site.Identity = mirror.IdentityGUID
+	wp = *NewWordPress(env, site)
+	wp.Fetch(time.Time{})
+	if n := len(wp.LoadAliases()); n != 1 {
+		t.Errorf("aliases sans checkpoint : %d, want 1 (of the prior run)", n)
+	}
+}
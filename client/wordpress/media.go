@@ -0,0 +1,77 @@
+package wordpress
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/sempernow/kit/types/convert"
+	"github.com/sempernow/uqc/client/mirror"
+)
+
+// MaxImageWidth is the widest size of a featured image preferred for a message.
+const MaxImageWidth = 1200
+
+// featuredMedia retrieves the featured image of a post,
+// from its embedded objects (per PostsEmbed) if exist, else from its (API) URI.
+// Returns nil if post has none, or if that is not an image.
+func (wp WP) featuredMedia(post *Post) *Media {
+	if post.FeaturedMedia == 0 {
+		return nil
+	}
+	m := Media{}
+	if post.Embedded != nil && len(post.Embedded.FeaturedMedia) > 0 {
+		mm := []Media{}
+		if err := json.Unmarshal(post.Embedded.FeaturedMedia, &mm); err == nil && len(mm) > 0 {
+			m = mm[0]
+		}
+	}
+	if m.SourceURL == "" {
+		j, err := wp.getWP(appendToURL(MediaURI, convert.IntToString(post.FeaturedMedia)))
+		if err != nil || j == "" {
+			return nil
+		}
+		if err := json.Unmarshal([]byte(j), &m); err != nil {
+			return nil
+		}
+	}
+	if m.SourceURL == "" || (m.MediaType != "" && m.MediaType != "image") {
+		return nil
+	}
+	return &m
+}
+
+// BestSize returns the widest uncropped size of the image not wider than MaxImageWidth,
+// else the original (full) size.
+func (m Media) BestSize() MediaSize {
+	full := MediaSize{
+		Width:     m.MediaDetails.Width,
+		Height:    m.MediaDetails.Height,
+		SourceURL: m.SourceURL,
+	}
+	best := MediaSize{}
+	for _, s := range m.MediaDetails.Sizes {
+		if s.SourceURL == "" || s.Width == 0 || s.Height == 0 || s.Width > MaxImageWidth {
+			continue
+		}
+		// Reject crops (thumbnail, ...) by their aspect ratio.
+		if full.Width > 0 && full.Height > 0 {
+			r := float64(full.Width) / float64(full.Height)
+			if math.Abs(float64(s.Width)/float64(s.Height)-r) > 0.02*r {
+				continue
+			}
+		}
+		if s.Width > best.Width {
+			best = s
+		}
+	}
+	if best.SourceURL == "" || (full.Width > 0 && full.Width <= MaxImageWidth) {
+		return full
+	}
+	return best
+}
+
+// figure renders the (featured) image as HTML to prepend to a message body.
+func figure(m *Media) string {
+	s := m.BestSize()
+	return mirror.Figure(s.SourceURL, m.AltText, s.Width, s.Height)
+}
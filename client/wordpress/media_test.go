@@ -0,0 +1,98 @@
+package wordpress
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
+)
+
+const embeddedPosts = `[
+	{
+		"id": 7,
+		"featured_media": 9,
+		"_links": {"wp:featuredmedia": [{"embeddable": true, "href": "https://x.com/wp-json/wp/v2/media/9"}]},
+		"_embedded": {"wp:featuredmedia": [{
+			"id": 9,
+			"source_url": "https://x.com/full.jpg",
+			"alt_text": "Alt",
+			"media_type": "image",
+			"media_details": {"width": 2400, "height": 1200, "sizes": {
+				"large": {"width": 1024, "height": 512, "source_url": "https://x.com/large.jpg"},
+				"thumbnail": {"width": 150, "height": 150, "source_url": "https://x.com/thumb.jpg"}
+			}}
+		}]}
+	},
+	{"id": 8, "featured_media": 10},
+	{"id": 11, "featured_media": 12, "_embedded": {"wp:featuredmedia": [{"id": 12, "source_url": "https://x.com/a.pdf", "media_type": "file"}]}},
+	{"id": 13}
+]`
+
+// testWP returns a WP of a site at an httptest server (handler); uncached, unpaused.
+func testWP(t *testing.T, handler http.HandlerFunc) WP {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	env := &client.Env{
+		Logger: log.New(io.Discard, "", 0),
+		Cache:  t.TempDir(),
+		Client: client.Client{Timeout: 5 * time.Second},
+	}
+	site := mirror.Site{UserHandle: "test", HostURL: srv.URL, ChnID: testChnID, RateLimit: mirror.Duration(time.Millisecond)}
+	return WP{Adapter: mirror.Adapter{Env: env, Site: &site}}
+}
+
+func TestFeaturedMediaEmbedded(t *testing.T) {
+	if !strings.Contains(PostsURI, PostsEmbed) || !strings.Contains(postsURI("pages"), PostsEmbed) {
+		t.Errorf("posts URI sans embed : %s", PostsURI)
+	}
+	var fetched int64
+	wp := testWP(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&fetched, 1)
+		if !strings.HasPrefix(r.URL.Path, "/wp-json/wp/v2/media/10") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"id": 10, "source_url": "https://x.com/fetched.jpg", "media_type": "image"}`))
+	})
+	posts := []Post{}
+	if err := json.Unmarshal([]byte(embeddedPosts), &posts); err != nil {
+		t.Fatal(err)
+	}
+
+	m := wp.featuredMedia(&posts[0])
+	if m == nil || m.SourceURL != "https://x.com/full.jpg" || m.AltText != "Alt" {
+		t.Fatalf("embedded : %+v", m)
+	}
+	if s := m.BestSize(); s.SourceURL != "https://x.com/large.jpg" || s.Width != 1024 {
+		t.Errorf("best size : %+v", s)
+	}
+	if n := atomic.LoadInt64(&fetched); n != 0 {
+		t.Errorf("embedded : fetched %d, want 0", n)
+	}
+
+	if m := wp.featuredMedia(&posts[1]); m == nil || m.SourceURL != "https://x.com/fetched.jpg" {
+		t.Errorf("not embedded : %+v", m)
+	}
+	if n := atomic.LoadInt64(&fetched); n != 1 {
+		t.Errorf("not embedded : fetched %d, want 1", n)
+	}
+
+	if m := wp.featuredMedia(&posts[2]); m != nil {
+		t.Errorf("not an image : %+v", m)
+	}
+	if m := wp.featuredMedia(&posts[3]); m != nil {
+		t.Errorf("none : %+v", m)
+	}
+	if n := atomic.LoadInt64(&fetched); n != 1 {
+		t.Errorf("fetched %d, want 1", n)
+	}
+}
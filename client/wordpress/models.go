@@ -1,38 +1,18 @@
 package wordpress
 
-import "github.com/sempernow/uqc/client"
+import (
+	"encoding/json"
+
+	"github.com/sempernow/uqc/client/mirror"
+)
 
 const DateZeroWP = "1970-01-01T00:00:00"
 
 // WP contains a WordPress site configuration
 type WP struct {
-	Env     *client.Env
-	Site    *Site
-	Cleaner func(string) string
-}
-
-// Site contains that required to map a WordPress site to a Uqrate Channel.
-type Site struct {
-	UserHandle string `json:"user_handle,omitempty"`
-	ChnSlug    string `json:"chn_slug,omitempty"`
-	HostURL    string `json:"host_url,omitempty"`
-	OwnerID    string `json:"owner_id,omitempty"`
-	ChnID      string `json:"chn_id,omitempty"`
-	Posts      []Post `json:"posts,omitempty"`
-	Error      string `json:"error,omitempty"`
-	Status     `json:"status,omitempty"`
-
-	// Endpoint : /wp-json
-	Name        string `json:"name,omitempty"`
-	Description string `json:"description,omitempty"`
-	URL         string `json:"url,omitempty"`
-	Home        string `json:"home,omitempty"`
-	GMTOffset   int    `json:"gmt_offset,omitempty"`
-}
-
-type Status struct {
-	Object string `json:"object,omitempty"`
-	Code   int    `json:"code,omitempty"`
+	mirror.Adapter
+	Aliases Aliases
+	Posts   []Post // Per SitePosts
 }
 
 // Docker configs paths
@@ -44,18 +24,31 @@ const (
 // WordPress REST API endpoints
 // https://developer.wordpress.org/rest-api/reference/
 const (
-	SiteURI    = "/wp-json/?_fields=name,description,url,home,gmt_offset"
-	PostsURI   = "/wp-json/wp/v2/posts?_fields=id,date,date_gmt,link,modified,modified_gmt,slug,GUID,title,content,excerpt,author,categories,tags,comment_status"
-	TagsURI    = "/wp-json/wp/v2/tags?_fields=id,name,slug,count&per_page=100"
-	CatsURI    = "/wp-json/wp/v2/categories?_fields=id,name,slug,count&per_page=100"
-	AuthorsURI = "/wp-json/wp/v2/users?_fields=id,name,slug,avatar_urls&per_page=100"
+	SiteURI     = "/wp-json/?_fields=name,description,url,home,gmt_offset,timezone_string,site_icon_url"
+	PostsURI    = "/wp-json/wp/v2/posts?_fields=" + PostsFields + PostsEmbed
+	TypesURI    = "/wp-json/wp/v2/types"
+	CommentsURI = "/wp-json/wp/v2/comments?_fields=id,post,parent,author_name,date,date_gmt,content,link&status=approve"
+	MediaURI    = "/wp-json/wp/v2/media?_fields=id,source_url,alt_text,media_type,media_details"
+	TagsURI     = "/wp-json/wp/v2/tags?_fields=id,name,slug,count&per_page=100"
+	CatsURI     = "/wp-json/wp/v2/categories?_fields=id,name,slug,count&per_page=100"
+	AuthorsURI  = "/wp-json/wp/v2/users?_fields=id,name,slug,avatar_urls&per_page=100"
 )
 
+// PostsFields are those requested of each post of any type;
+// _links and _embedded being those of its featured media embedded per PostsEmbed.
+const PostsFields = "id,type,status,sticky,date,date_gmt,link,modified,modified_gmt,slug,guid,title,content,excerpt,author,categories,tags,comment_status,featured_media,_links,_embedded"
+
+// PostsEmbed requests the featured media of each post embedded therein, sparing a request per post; see featuredMedia.
+const PostsEmbed = "&_embed=wp:featuredmedia"
+
 // Post contains a subset of keys from its WordPress
 // REST API namesake of the Posts endpoint.
 // https://developer.wordpress.org/rest-api/reference/posts/
 type Post struct {
 	ID          int      `json:"id,omitempty"`
+	Type        string   `json:"type,omitempty"`   // post, page, podcast
+	Status      string   `json:"status,omitempty"` // publish, future, draft, pending, private
+	Sticky      bool     `json:"sticky,omitempty"`
 	Date        string   `json:"date,omitempty"`         // @ New
 	DateGMT     string   `json:"date_gmt,omitempty"`     // @ New
 	Modified    string   `json:"modified,omitempty"`     // @ Edit
@@ -70,11 +63,46 @@ type Post struct {
 	Categories  []int    `json:"categories,omitempty"`
 	Tags        []int    `json:"tags,omitempty"`
 
+	FeaturedMedia int       `json:"featured_media,omitempty"`
+	Embedded      *Embedded `json:"_embedded,omitempty"` // Per PostsEmbed
+
 	// Code  int    `json:"code,omitempty"`
 	Error string `json:"error,omitempty"`
 }
 
 // Rendered contains that of certain Post keys.
 type Rendered struct {
-	Rendered string `json:"rendered,omitempty"`
+	Rendered  string `json:"rendered,omitempty"`
+	Protected bool   `json:"protected,omitempty"` // @ Content, Excerpt : password protected
+}
+
+// Embedded contains those objects of a Post embedded per its request (see PostsEmbed).
+// Decoding of each is deferred, else a malformed one would fail the entire Post.
+type Embedded struct {
+	FeaturedMedia json.RawMessage `json:"wp:featuredmedia,omitempty"`
+}
+
+// Media contains a subset of keys from its WordPress
+// REST API namesake of the Media endpoint.
+// https://developer.wordpress.org/rest-api/reference/media/
+type Media struct {
+	ID           int          `json:"id,omitempty"`
+	SourceURL    string       `json:"source_url,omitempty"`
+	AltText      string       `json:"alt_text,omitempty"`
+	MediaType    string       `json:"media_type,omitempty"` // image, file
+	MediaDetails MediaDetails `json:"media_details,omitempty"`
+}
+
+// MediaDetails contains the original dimensions and the generated sizes of an image.
+type MediaDetails struct {
+	Width  int                  `json:"width,omitempty"`
+	Height int                  `json:"height,omitempty"`
+	Sizes  map[string]MediaSize `json:"sizes,omitempty"` // thumbnail, medium, large, full, ...
+}
+
+// MediaSize contains that of one (generated) size of an image.
+type MediaSize struct {
+	Width     int    `json:"width,omitempty"`
+	Height    int    `json:"height,omitempty"`
+	SourceURL string `json:"source_url,omitempty"`
 }
@@ -0,0 +1,365 @@
+package wordpress
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
+	"github.com/sempernow/uqc/client/yaml"
+)
+
+// SchemaVersion is the latest of the structured (JSON or YAML) sites list, declared per its version field.
+const SchemaVersion = 1
+
+// Formats of the sites-list file
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// ReadSitesList reads the records of the sites-list file, from Docker config if exist, else from assets;
+// sans the dynamic fields of each (see SiteGot). A malformed record is returned as a Site of its Error,
+// so that all records are read regardless. Returns error only if the file is not read.
+//
+// The file is of any format (see Format): CSV, its fields either per header (see SetField), else per ColumnsCSV;
+// else JSON or YAML of the schema:
+//
+//	version: 1
+//	sites:
+//	  - user_handle: foo
+//	    slug: bar
+//	    host_url: https://foo.bar
+//	    owner_id: <UUID>
+//	    chn_id: <UUID>
+//	    source: feed          # Any option; see SetOption
+//	    types: [posts, pages]
+//	    enabled: false
+func ReadSitesList(env *client.Env) ([]mirror.Site, error) {
+	bb, _, err := readSitesListFile(env)
+	if err != nil {
+		return []mirror.Site{}, err
+	}
+	return decodeSites(bb)
+}
+
+// readSitesListFile reads the sites-list file, from Docker config if exist, else from assets; returns its content and path.
+func readSitesListFile(env *client.Env) ([]byte, string, error) {
+	env.Logger.Printf("INFO : Try read %s from Docker config\n", PathCfgSitesListCSV)
+	bb, err := os.ReadFile(PathCfgSitesListCSV)
+	if err == nil {
+		return bb, PathCfgSitesListCSV, nil
+	}
+	path := filepath.Join(env.Assets, env.SitesListCSV)
+	env.Logger.Printf("INFO : Try read %s from cache\n", env.SitesListCSV)
+	bb, err = os.ReadFile(path)
+	return bb, path, err
+}
+
+// decodeSites decodes the records of a sites-list file of any format; see ReadSitesList.
+func decodeSites(bb []byte) ([]mirror.Site, error) {
+	switch Format(bb) {
+	case FormatJSON:
+		var doc interface{}
+		if err := json.Unmarshal(bb, &doc); err != nil {
+			return []mirror.Site{}, errors.Wrap(err, "decoding sites list (JSON)")
+		}
+		return decodeSitesList(doc)
+	case FormatYAML:
+		doc, err := yaml.Parse(string(bb))
+		if err != nil {
+			return []mirror.Site{}, errors.Wrap(err, "decoding sites list (YAML)")
+		}
+		return decodeSitesList(doc)
+	}
+	return readSitesCSV(bb), nil
+}
+
+// EditSitesList applies an edit to the records of the sites-list file (see ReadSitesList),
+// and writes those anew in the format thereof (see EncodeSitesList); a CSV file is written with header.
+// Both its Docker-config and assets copies are written, the former only if exist, and first,
+// so that neither is changed if that is not writable. Comments of the file are not kept.
+// A file of any malformed record is not edited.
+func EditSitesList(env *client.Env, edit func([]mirror.Site) ([]mirror.Site, error)) error {
+	bb, _, err := readSitesListFile(env)
+	if err != nil {
+		return errors.Wrap(err, "reading sites list")
+	}
+	sites, err := decodeSites(bb)
+	if err != nil {
+		return err
+	}
+	for _, site := range sites {
+		if site.Error != "" {
+			return errors.Errorf("sites list : row %d : %s (see siteslist validate)", site.Row, site.Error)
+		}
+	}
+	if sites, err = edit(sites); err != nil {
+		return err
+	}
+	out, err := EncodeSitesList(sites, Format(bb))
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(PathCfgSitesListCSV); err == nil {
+		if err := os.WriteFile(PathCfgSitesListCSV, out, 0664); err != nil {
+			return errors.Wrap(err, "writing sites list to Docker config")
+		}
+	}
+	if err := os.WriteFile(filepath.Join(env.Assets, env.SitesListCSV), out, 0664); err != nil {
+		return errors.Wrap(err, "writing sites list to assets")
+	}
+	return nil
+}
+
+// EncodeSitesList renders the records of sites (see Site.Record) as a sites-list file of a format;
+// CSV of header-named fields (ColumnsCSV and options), else JSON or YAML of the latest schema version.
+func EncodeSitesList(sites []mirror.Site, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case FormatCSV:
+		w := csv.NewWriter(&buf)
+		w.Write(append(append([]string{}, mirror.ColumnsCSV...), "options"))
+		for _, site := range sites {
+			cc := []string{}
+			for _, kv := range site.Record()[:len(mirror.ColumnsCSV)] {
+				cc = append(cc, kv[1])
+			}
+			w.Write(append(cc, site.Options()))
+		}
+		w.Flush()
+		return buf.Bytes(), w.Error()
+
+	case FormatJSON:
+		list := []map[string]interface{}{}
+		for _, site := range sites {
+			fields := map[string]interface{}{}
+			for _, kv := range site.Record() {
+				if kv[1] == "" {
+					continue
+				}
+				fields[kv[0]] = recordValue(kv[0], kv[1])
+			}
+			list = append(list, fields)
+		}
+		bb, err := json.MarshalIndent(map[string]interface{}{
+			"version": SchemaVersion,
+			"sites":   list,
+		}, "", "  ")
+		return append(bb, '\n'), err
+
+	case FormatYAML:
+		fmt.Fprintf(&buf, "version: %d\nsites:\n", SchemaVersion)
+		for _, site := range sites {
+			item := "  - "
+			for _, kv := range site.Record() {
+				if kv[1] == "" {
+					continue
+				}
+				val := yaml.Quote(kv[1])
+				if ss, ok := recordValue(kv[0], kv[1]).([]string); ok {
+					for i := range ss {
+						ss[i] = yaml.Quote(ss[i])
+					}
+					val = "[" + strings.Join(ss, ", ") + "]"
+				}
+				fmt.Fprintf(&buf, "%s%s: %s\n", item, kv[0], val)
+				item = "    "
+			}
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, errors.Errorf("unknown format of sites list : %s", format)
+}
+
+// recordValue returns that of a structured (JSON or YAML) record per its key; lists as such, else the value as is.
+func recordValue(key, val string) interface{} {
+	switch key {
+	case "cleaners", "types":
+		return strings.Split(val, ",")
+	case "comments", "enabled":
+		b, _ := strconv.ParseBool(val)
+		return b
+	}
+	return val
+}
+
+// Format returns that of a sites-list file per its content.
+func Format(bb []byte) string {
+	bb = bytes.TrimLeft(bytes.TrimPrefix(bb, []byte("\ufeff")), " \t\r\n")
+	if bytes.HasPrefix(bb, []byte("{")) {
+		return FormatJSON
+	}
+	for _, line := range strings.Split(string(bb), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line == "---" || strings.HasPrefix(line, "version:") || strings.HasPrefix(line, "sites:") {
+			return FormatYAML
+		}
+		break
+	}
+	return FormatCSV
+}
+
+// readSitesCSV reads the records of a sites-list CSV file; its fields named per its header,
+// if that declares (at least) owner_id and chn_id, else per ColumnsCSV.
+func readSitesCSV(bb []byte) []mirror.Site {
+	sites := []mirror.Site{}
+	r := csv.NewReader(bytes.NewReader(bb))
+	r.FieldsPerRecord = -1 // The options field is optional.
+
+	var header []string
+	for {
+		cc, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			row := 0
+			if pe, ok := err.(*csv.ParseError); ok {
+				row = pe.StartLine
+			}
+			sites = append(sites, mirror.Site{Row: row, Error: err.Error()})
+			continue
+		}
+		row, _ := r.FieldPos(0)
+		if header == nil && isHeader(cc) {
+			header = cc
+			continue
+		}
+		if header == nil && len(cc) > 1 && cc[1] == "slug" {
+			continue // Header of positional fields
+		}
+		site := mirror.Site{Row: row}
+		if header != nil {
+			for i, val := range cc {
+				if i >= len(header) {
+					site.Error = "malformed CSV : more fields than header"
+					break
+				}
+				if err := site.SetField(header[i], val); err != nil {
+					site.Error = err.Error()
+					break
+				}
+			}
+			sites = append(sites, site)
+			continue
+		}
+		if len(cc) < len(mirror.ColumnsCSV) {
+			sites = append(sites, mirror.Site{Row: row, UserHandle: cc[0], Error: "malformed CSV : too few fields"})
+			continue
+		}
+		for i, key := range mirror.ColumnsCSV {
+			site.SetField(key, cc[i])
+		}
+		if len(cc) > len(mirror.ColumnsCSV) {
+			if err := site.SetOptions(cc[len(mirror.ColumnsCSV)]); err != nil {
+				site.Error = err.Error()
+			}
+		}
+		sites = append(sites, site)
+	}
+	return sites
+}
+
+// isHeader reports whether a CSV record is a header naming its fields.
+func isHeader(cc []string) bool {
+	names := map[string]bool{}
+	for _, c := range cc {
+		names[strings.ToLower(strings.TrimSpace(c))] = true
+	}
+	return names["owner_id"] && names["chn_id"]
+}
+
+// decodeSitesList decodes the sites of a structured (JSON or YAML) sites list (doc) per its schema version.
+func decodeSitesList(doc interface{}) ([]mirror.Site, error) {
+	sites := []mirror.Site{}
+	top, ok := doc.(map[string]interface{})
+	if !ok {
+		return sites, errors.New("malformed sites list : not an object")
+	}
+	v, err := strconv.Atoi(scalarOf(top["version"]))
+	if err != nil {
+		return sites, errors.Errorf("malformed sites list : version : %q", scalarOf(top["version"]))
+	}
+	if v < 1 || v > SchemaVersion {
+		return sites, errors.Errorf("unsupported sites list version : %d (latest %d)", v, SchemaVersion)
+	}
+	list, ok := top["sites"].([]interface{})
+	if !ok {
+		return sites, errors.New("malformed sites list : sites : not a list")
+	}
+	for i, el := range list {
+		site := mirror.Site{Row: i + 1}
+		fields, ok := el.(map[string]interface{})
+		if !ok {
+			site.Error = "malformed site : not an object"
+			sites = append(sites, site)
+			continue
+		}
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			val, err := valueOf(fields[k])
+			if err != nil {
+				err = errors.Wrap(err, k)
+			} else {
+				err = site.SetField(k, val)
+			}
+			if err != nil && site.Error == "" {
+				site.Error = err.Error() // The first of its errors
+			}
+		}
+		sites = append(sites, site)
+	}
+	return sites, nil
+}
+
+// valueOf renders a (decoded) field value as that of an option; a list as comma delimited.
+func valueOf(v interface{}) (string, error) {
+	switch x := v.(type) {
+	case nil:
+		return "", nil
+	case []interface{}:
+		ss := []string{}
+		for _, el := range x {
+			s, err := valueOf(el)
+			if err != nil {
+				return "", err
+			}
+			ss = append(ss, s)
+		}
+		return strings.Join(ss, ","), nil
+	case map[string]interface{}:
+		return "", errors.New("malformed value : object")
+	}
+	return scalarOf(v), nil
+}
+
+// scalarOf renders a (decoded) scalar value as string.
+func scalarOf(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package wordpress
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sempernow/uqc/client/mirror"
+)
+
+// testSites returns sites of most every field and option, some of values to be quoted in YAML.
+func testSites(t *testing.T) []mirror.Site {
+	t.Helper()
+	records := [][][2]string{
+		{
+			{"user_handle", "foo"}, {"slug", "bar"}, {"host_url", "https://foo.bar"},
+			{"owner_id", "af9a4e4a-1c5b-4a0f-9b44-8e7b1c1f0a01"}, {"chn_id", testChnID},
+		},
+		{
+			{"user_handle", "baz"}, {"slug", "- dash"}, {"host_url", "https://baz.example"},
+			{"owner_id", "af9a4e4a-1c5b-4a0f-9b44-8e7b1c1f0a02"}, {"chn_id", "0b0f4cbe-7b0e-4a59-a7c5-2b7c1f0b0a02"},
+			{"source", "feed"}, {"cleaners", "none"}, {"summary", mirror.SummaryText}, {"summary_sentences", "3"},
+			{"types", "posts,pages"}, {"api", mirror.APIRestRoute}, {"api_root", "https://baz.example/?rest_route=/"},
+			{"comments", "true"}, {"identity", mirror.IdentityGUID}, {"feed_url", "https://baz.example/feed?a=1&b=#2"},
+			{"dir", "/srv/it's"}, {"actor", "@me@x.social"}, {"rate_limit", "1.5s"}, {"tag_policy", mirror.TagsCats},
+			{"enabled", "false"}, {"schedule", "1h0m0s"},
+		},
+	}
+	sites := []mirror.Site{}
+	for i, kvs := range records {
+		site := mirror.Site{Row: i + 1}
+		for _, kv := range kvs {
+			if err := site.SetField(kv[0], kv[1]); err != nil {
+				t.Fatalf("SetField(%q, %q) : %s", kv[0], kv[1], err)
+			}
+		}
+		sites = append(sites, site)
+	}
+	return sites
+}
+
+func TestEncodeDecodeSitesList(t *testing.T) {
+	sites := testSites(t)
+	for _, format := range []string{FormatCSV, FormatJSON, FormatYAML} {
+		bb, err := EncodeSitesList(sites, format)
+		if err != nil {
+			t.Fatalf("%s : %s", format, err)
+		}
+		if got := Format(bb); got != format {
+			t.Errorf("%s : Format = %s", format, got)
+		}
+		got, err := decodeSites(bb)
+		if err != nil {
+			t.Fatalf("%s : %s\n%s", format, err, bb)
+		}
+		if len(got) != len(sites) {
+			t.Fatalf("%s : %d sites, want %d\n%s", format, len(got), len(sites), bb)
+		}
+		for i := range sites {
+			if got[i].Error != "" {
+				t.Errorf("%s : site %d : %s", format, i, got[i].Error)
+			}
+			if !reflect.DeepEqual(got[i].Record(), sites[i].Record()) {
+				t.Errorf("%s : site %d\n got: %q\nwant: %q\n%s", format, i, got[i].Record(), sites[i].Record(), bb)
+			}
+		}
+	}
+	if _, err := EncodeSitesList(sites, "toml"); err == nil {
+		t.Error("unknown format : want error")
+	}
+}
+
+func TestDecodeSitesYAML(t *testing.T) {
+	doc := `# Sites
+version: 1
+sites:
+  - user_handle: foo   # comment
+    slug: "bar"
+    host_url: https://foo.bar
+    owner_id: af9a4e4a-1c5b-4a0f-9b44-8e7b1c1f0a01
+    chn_id: ` + testChnID + `
+    types: [posts, 'pages']
+    enabled: false
+  -
+    user_handle: baz
+    rate_limit: soon
+  - not a site
+`
+	sites, err := decodeSites([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sites) != 3 {
+		t.Fatalf("sites : %d, want 3", len(sites))
+	}
+	s := sites[0]
+	if s.Error != "" || s.UserHandle != "foo" || s.ChnSlug != "bar" || !s.Disabled ||
+		len(s.Types) != 2 || s.Types[1] != "pages" {
+		t.Errorf("site : %+v", s)
+	}
+	if sites[1].UserHandle != "baz" || sites[1].Error == "" || sites[1].Row != 2 {
+		t.Errorf("malformed option : %+v", sites[1])
+	}
+	if sites[2].Error == "" {
+		t.Errorf("not an object : %+v", sites[2])
+	}
+
+	for _, bad := range []string{
+		"version: 2\nsites: []\n",
+		"version: x\nsites:\n  - user_handle: foo\n",
+		"version: 1\nsites: foo\n",
+		"version: 1\nsites:\n  - user_handle: foo\n     slug: bar\n",
+	} {
+		if _, err := decodeSites([]byte(bad)); err == nil {
+			t.Errorf("decodeSites(%q) : want error", bad)
+		}
+	}
+}
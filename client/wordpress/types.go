@@ -0,0 +1,63 @@
+package wordpress
+
+import (
+	"encoding/json"
+	"log"
+)
+
+// PostType contains a subset of keys from its WordPress
+// REST API namesake of the Types endpoint.
+// https://developer.wordpress.org/rest-api/reference/post-types/
+type PostType struct {
+	Name          string `json:"name,omitempty"`
+	Slug          string `json:"slug,omitempty"`           // post, page, podcast
+	RestBase      string `json:"rest_base,omitempty"`      // posts, pages, podcasts
+	RestNamespace string `json:"rest_namespace,omitempty"` // wp/v2 (WordPress 5.9+)
+}
+
+// DefaultRestBase is that mirrored of sites declaring no (post) types.
+const DefaultRestBase = "posts"
+
+// postsURI returns the (API) URI of posts of a type per its REST base.
+func postsURI(restBase string) string {
+	return "/wp-json/wp/v2/" + restBase + "?_fields=" + PostsFields + PostsEmbed
+}
+
+// RestBases returns the REST base of each (post) type declared of the site (Site.Types),
+// validating each exists and is REST enabled per discovery at its (API) URI.
+// The type may be declared by its slug (podcast) or its REST base (podcasts).
+func (wp WP) RestBases() []string {
+	if len(wp.Site.Types) == 0 {
+		return []string{DefaultRestBase}
+	}
+	j, err := wp.getWP(TypesURI)
+	if err != nil || j == "" {
+		log.Printf("ERR : types @ %s : %v\n", wp.Site.HostURL, err)
+		return []string{}
+	}
+	types := map[string]PostType{}
+	if err := json.Unmarshal([]byte(j), &types); err != nil {
+		log.Printf("ERR : Unmarshalling : %s\n", err.Error())
+		return []string{}
+	}
+	bases := []string{}
+	for _, want := range wp.Site.Types {
+		got := false
+		for slug, t := range types {
+			if want != slug && want != t.RestBase {
+				continue
+			}
+			if t.RestBase == "" || (t.RestNamespace != "" && t.RestNamespace != "wp/v2") {
+				log.Printf("WARN : type '%s' @ %s : NOT at REST API (wp/v2)\n", want, wp.Site.HostURL)
+				break
+			}
+			bases = append(bases, t.RestBase)
+			got = true
+			break
+		}
+		if !got {
+			log.Printf("WARN : type '%s' @ %s : NOT FOUND\n", want, wp.Site.HostURL)
+		}
+	}
+	return bases
+}
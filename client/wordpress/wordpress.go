@@ -3,104 +3,182 @@
 package wordpress
 
 import (
-	"bytes"
-	"encoding/csv"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
+	"math"
+	neturl "net/url"
 	"os"
-	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
-	"github.com/sempernow/kit/id"
 	"github.com/sempernow/kit/types/convert"
-	"github.com/sempernow/kit/types/str"
 	"github.com/sempernow/uqc/client"
+	"github.com/sempernow/uqc/client/mirror"
 )
 
 // NewWordPress contains app environment and per-site configuration.
-func NewWordPress(env *client.Env, site *Site) *WP {
-	return &WP{
-		Env:  env,
-		Site: site,
+func NewWordPress(env *client.Env, site *mirror.Site) *WP {
+	wp := &WP{
+		Adapter: mirror.NewAdapter(env, site),
+		Aliases: Aliases{},
 	}
+	if site.Identity != "" && site.Identity != mirror.IdentityURI {
+		wp.Aliases = wp.LoadAliases()
+	}
+	return wp
 }
 
-// MakeSitesList creates []Sites from a CSV file.
+// MakeSitesList creates []Sites from the sites-list file (see ReadSitesList).
 // Those values are the export of an SQL query (hosts_channels.sql)
-// for relevant records (users and channels) in Uqrate data store.
-func MakeSitesList(env *client.Env) []Site {
-
-	sites := []Site{}
-
-	// Open the sites-list CSV file
-
-	env.Logger.Printf("INFO : Try read %s from Docker config\n", PathCfgSitesListCSV)
-	bb, err := os.ReadFile(PathCfgSitesListCSV)
+// for relevant records (users and channels) in Uqrate data store,
+// optionally appended with per-site options (see SetOptions).
+// Each is described per its source (describe) concurrently; see describeSites.
+func MakeSitesList(env *client.Env, describe Describer) []mirror.Site {
+	sites, err := ReadSitesList(env)
 	if err != nil {
-		env.Logger.Printf("INFO : Try read %s from cache\n", env.SitesListCSV)
-		bb, err = os.ReadFile(filepath.Join(env.Assets, env.SitesListCSV))
-		if err != nil {
-			env.Logger.Printf("ERR @ ReadFile : %s\n", err.Error())
-			return sites
-		}
+		env.Logger.Printf("ERR @ ReadFile : %s\n", err.Error())
+		return sites
 	}
-	r := csv.NewReader(bytes.NewReader(bb))
+	describeSites(env, sites, describe)
+	return sites
+}
 
-	// Append each CSV record to sites list.
-	for {
-		cc, err := r.Read()
-		if err == io.EOF {
-			break
+// MakeSitesListFromService makes a new sites list per the hosted (mirror) channels of Uqrate's API
+// (see client.GetHostedChannels), sans access to its database. Each is merged with that (by ChnID)
+// of the sites-list file, if any, whose options (source, auth, ...) are kept,
+// and then with the dynamic fields of its site per its source (describe).
+func MakeSitesListFromService(env *client.Env, describe Describer) ([]mirror.Site, error) {
+	sites := []mirror.Site{}
+	jwt := NewWordPress(env, &mirror.Site{}).GetTkn()
+	if jwt == "" {
+		return sites, errors.Errorf("token of operator (%s) UNAVAILABLE", env.Client.User)
+	}
+	rsp := env.GetHostedChannels(jwt)
+	if rsp.Error != "" {
+		return sites, errors.Errorf("GetHostedChannels : HTTP %d : %s", rsp.Code, rsp.Error)
+	}
+	chns := []client.HostedChannel{}
+	if err := json.Unmarshal([]byte(rsp.Body), &chns); err != nil {
+		return sites, errors.Wrap(err, "decoding hosted channels")
+	}
+
+	// Local records, if any, by ChnID
+	local := map[string]mirror.Site{}
+	if ss, err := ReadSitesList(env); err == nil {
+		for _, s := range ss {
+			if s.Error == "" && s.ChnID != "" {
+				local[strings.ToLower(s.ChnID)] = s
+			}
 		}
-		if err != nil {
-			sites[0].Error = err.Error()
-			return sites
+	}
+	for i, chn := range chns {
+		site, ok := local[strings.ToLower(chn.ChnID)]
+		if !ok {
+			site = mirror.Site{}
 		}
-		if len(cc) < 5 {
-			sites[0].Error = errors.New("malformed CSV : too few fields").Error()
-			return sites
+		site.Row = i + 1
+		site.UserHandle = chn.UserHandle
+		site.OwnerID = chn.OwnerID
+		site.ChnID = chn.ChnID
+		site.ChnSlug = chn.ChnSlug
+		if chn.HostURL != "" {
+			site.HostURL = chn.HostURL
 		}
-		if cc[1] == "slug" {
-			continue
+		sites = append(sites, site)
+	}
+	env.Logger.Printf("INFO : hosted channels : %d (of sites list : %d)\n", len(sites), len(local))
+	describeSites(env, sites, describe)
+	return sites, nil
+}
+
+// Describer gets the dynamic fields of a site of a sites list, by reference, per its source (Site.Source).
+type Describer func(env *client.Env, site *mirror.Site)
+
+// DefaultWorkers is the number of sites described concurrently (see describeSites) per Env declaring none.
+const DefaultWorkers = 8
+
+// describeSites gets the dynamic fields of each (valid, enabled) site of a sites list, by reference;
+// concurrently per env.Client.Workers, yet serially per host, so that each host (of API) is requested
+// no more often than per the rate limit of its site(s) (see Pause), and a hung host stalls only its worker.
+// Sites remain in order of the list. Progress is logged per site.
+func describeSites(env *client.Env, sites []mirror.Site, describe Describer) {
+	workers := env.Client.Workers
+	if workers < 1 {
+		workers = DefaultWorkers
+	}
+
+	// Sites (indices) per host of API (see apiHost), in order of first appearance
+	hosts := []string{}
+	byHost := map[string][]int{}
+	for i, site := range sites {
+		h := apiHost(site)
+		if h == "" {
+			h = fmt.Sprintf("#%d", i) // Of no host; each its own
 		}
-		// Get additional site params dynamically and merge with those from CSV.
-		wp := NewWordPress(env, &Site{HostURL: cc[2]})
-		wp.Site = &Site{
-			UserHandle: cc[0],
-			ChnSlug:    cc[1],
-			HostURL:    cc[2],
-			OwnerID:    cc[3],
-			ChnID:      cc[4],
+		if _, ok := byHost[h]; !ok {
+			hosts = append(hosts, h)
 		}
-		wp.SiteGot()
+		byHost[h] = append(byHost[h], i)
+	}
+	if workers > len(hosts) {
+		workers = len(hosts)
+	}
 
-		sites = append(sites, *wp.Site)
+	var (
+		jobs = make(chan []int)
+		wg   sync.WaitGroup
+		done int64
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ii := range jobs {
+				for _, i := range ii {
+					describe(env, &sites[i])
+					n := atomic.AddInt64(&done, 1)
+					env.Logger.Printf("INFO : sites list : described %d of %d : %s\n", n, len(sites), sites[i].UserHandle)
+				}
+			}
+		}()
 	}
-	return sites
+	for _, h := range hosts {
+		jobs <- byHost[h]
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// apiHost returns the host of the API of a site (see apiURL); e.g., that of WordPress.com of all sites hosted thereat,
+// and that of its APIRoot if declared (or discovered prior) of another host. That of its HostURL if not of WordPress.
+func apiHost(site mirror.Site) string {
+	if site.Source != "" && site.Source != "wordpress" {
+		return mirror.Host(site.HostURL)
+	}
+	wp := WP{Adapter: mirror.Adapter{Site: &site}}
+	if site.API == "" && wp.isWPCOM() {
+		site.API = mirror.APIWPCOM
+	}
+	return mirror.Host(wp.apiURL(SiteURI))
 }
 
 // GetSitesList retrieves []Sites list from its cache (JSON)
-// if exist, else makes and caches anew.
-func GetSitesList(env *client.Env) []Site {
-	sites := []Site{}
-	env.Logger.Printf("INFO : Try read %s from Docker config\n", PathCfgSitesListJSON)
-	j, err := os.ReadFile(PathCfgSitesListJSON)
-	if err != nil {
-		env.Logger.Printf("INFO : Try read %s from cache\n", env.SitesListJSON)
-		j = env.GetCache(env.SitesListJSON)
-		if len(j) == 0 {
-			env.Logger.Printf("INFO : Make new sites list\n")
-			sites = MakeSitesList(env)
-			if err := env.SetCache(env.SitesListJSON, convert.Stringify(sites)); err != nil {
-				env.Logger.Printf("ERR : setting cache\n")
-				return sites
-			}
-			j = env.GetCache(env.SitesListJSON)
+// if exist, else makes (see MakeSitesList) and caches anew.
+func GetSitesList(env *client.Env, describe Describer) []mirror.Site {
+	sites := []mirror.Site{}
+	j := readSitesListJSON(env)
+	if len(j) == 0 {
+		env.Logger.Printf("INFO : Make new sites list\n")
+		sites = MakeSitesList(env, describe)
+		if err := env.SetCache(env.SitesListJSON, convert.Stringify(sites)); err != nil {
+			env.Logger.Printf("ERR : setting cache\n")
+			return sites
 		}
+		j = env.GetCache(env.SitesListJSON)
 	}
 	if len(j) == 0 {
 		env.Logger.Printf("ERR : sites list (%s) EMPTY or NOT FOUND\n", env.SitesListJSON)
@@ -112,12 +190,64 @@ func GetSitesList(env *client.Env) []Site {
 	return sites
 }
 
+// ReadSitesListJSON reads the sites list (JSON) from its Docker config if exist, else from its cache;
+// sans making it anew (see GetSitesList). Returns empty list if neither exist.
+func ReadSitesListJSON(env *client.Env) ([]mirror.Site, error) {
+	sites := []mirror.Site{}
+	j := readSitesListJSON(env)
+	if len(j) == 0 {
+		return sites, nil
+	}
+	if err := json.Unmarshal(j, &sites); err != nil {
+		return sites, errors.Wrapf(err, "unmarshalling json of '%s'", env.SitesListJSON)
+	}
+	return sites, nil
+}
+
+// readSitesListJSON reads the sites list (JSON) from its Docker config if exist, else from its cache; empty if neither.
+func readSitesListJSON(env *client.Env) []byte {
+	env.Logger.Printf("INFO : Try read %s from Docker config\n", PathCfgSitesListJSON)
+	j, err := os.ReadFile(PathCfgSitesListJSON)
+	if err != nil {
+		env.Logger.Printf("INFO : Try read %s from cache\n", env.SitesListJSON)
+		j = env.GetCache(env.SitesListJSON)
+	}
+	return j
+}
+
+// SaveSitesList writes the sites list (JSON) to its Docker config, if exist, and then to its cache;
+// the latter not if the former fails, so that the two are consistent.
+func SaveSitesList(env *client.Env, sites []mirror.Site) error {
+	if _, err := os.Stat(PathCfgSitesListJSON); err == nil {
+		if err := os.WriteFile(PathCfgSitesListJSON, []byte(convert.Stringify(sites)), 0664); err != nil {
+			return errors.Wrap(err, "writing sites list (JSON) to Docker config")
+		}
+	}
+	return env.SetCache(env.SitesListJSON, convert.Stringify(sites))
+}
+
 // SiteGot retrieves dynamic fields of Site from a site,
 // and merges it into existing site record (wp.Site) by reference.
+// The API root of a self-hosted site is discovered per its home page.
+// Sites of undeclared API mode fall back to that of WordPress.com if its /wp-json fails.
 func (wp WP) SiteGot() {
+	if wp.Site.API == "" && wp.isWPCOM() {
+		wp.Site.API = mirror.APIWPCOM
+	}
+	if wp.Site.API != mirror.APIWPCOM && wp.Site.APIRoot == "" {
+		wp.discoverAPIRoot()
+	}
 
 	j, err := wp.getWP(SiteURI)
 
+	if (err != nil || j == "") && wp.Site.API == "" {
+		wp.Site.API = mirror.APIWPCOM
+		if jj, e := wp.getWP(SiteURI); e == nil && jj != "" {
+			j, err = jj, nil
+		} else {
+			wp.Site.API = ""
+		}
+	}
 	if err != nil {
 		wp.Site.Error = err.Error()
 		return
@@ -126,30 +256,161 @@ func (wp WP) SiteGot() {
 		wp.Site.Error = "GET returned nothing"
 		return
 	}
-	if err := json.Unmarshal([]byte(j), &wp.Site); err != nil {
+	if wp.Site.API == mirror.APIWPCOM {
+		err = wp.unmarshalWPCOMSite(j)
+	} else {
+		err = json.Unmarshal([]byte(j), &wp.Site)
+	}
+	if err != nil {
 		wp.Site.Error = err.Error()
 		log.Printf("ERR : Unmarshalling : %s\n", err.Error())
 	}
 }
 
-// SitePosts retrieves wp.Site.Posts; the WordPress-normalized []Post list from a Site.
-func (wp WP) SitePosts() {
-	j, err := wp.getWP(PostsURI)
-	if err != nil {
-		wp.Site.Error = err.Error()
-		return
+// SitePosts retrieves wp.Posts; the WordPress-normalized []Post list from a Site,
+// of all (post) types declared thereof.
+// Posts not to be mirrored (see skipReason) are recorded at wp.Site.Skipped instead.
+func (wp *WP) SitePosts() {
+	for _, base := range wp.RestBases() {
+		uri := postsURI(base)
+		if wp.Site.Auth != "" {
+			uri += "&status=publish"
+		}
+		j, err := wp.getWP(uri)
+		if err != nil {
+			wp.Site.Error = err.Error()
+			continue
+		}
+		if j == "" {
+			wp.Site.Error = "GET returned nothing"
+			continue
+		}
+		posts := []Post{}
+		if err := json.Unmarshal([]byte(j), &posts); err != nil {
+			wp.Site.Error = err.Error()
+			log.Printf("ERR : Unmarshalling : %s\n", err.Error())
+			continue
+		}
+		for _, post := range posts {
+			if reason := skipReason(&post); reason != "" {
+				log.Printf("INFO : SKIP post %d @ %s : %s\n", post.ID, wp.Site.HostURL, reason)
+				wp.Site.Skipped = append(wp.Site.Skipped, mirror.Skip{ID: post.ID, Link: post.Link, Reason: reason})
+				continue
+			}
+			wp.Posts = append(wp.Posts, post)
+		}
 	}
-	if j == "" {
-		wp.Site.Error = "GET returned nothing"
-		return
+}
+
+// PerPage is the page size of posts retrieved per AllPosts; the maximum of the WordPress REST API.
+const PerPage = 100
+
+// AllPosts retrieves all posts of all (post) types declared of the site, page by page; sans cache,
+// and sans those of skipReason. Unlike SitePosts, which retrieves the latest (page) of each only.
+func (wp WP) AllPosts() ([]Post, error) {
+	all := []Post{}
+	for _, base := range wp.RestBases() {
+		uri := postsURI(base)
+		if wp.Site.Auth != "" {
+			uri += "&status=publish"
+		}
+		if err := wp.pages(uri, func(body string) (int, error) {
+			posts := []Post{}
+			if err := json.Unmarshal([]byte(body), &posts); err != nil {
+				return 0, errors.Wrap(err, "unmarshalling posts")
+			}
+			all = append(all, posts...)
+			return len(posts), nil
+		}); err != nil {
+			return all, err
+		}
 	}
+	return all, nil
+}
 
-	if err := json.Unmarshal([]byte(j), &wp.Site.Posts); err != nil {
-		wp.Site.Error = err.Error()
-		log.Printf("ERR : Unmarshalling : %s\n", err.Error())
+// pages retrieves each page of a list endpoint (uri) of PerPage items, sans cache, passing its body to decode,
+// which returns the number of its items. Pages are followed until the last per X-WP-TotalPages, else a short page.
+func (wp WP) pages(uri string, decode func(body string) (int, error)) error {
+	for page := 1; ; page++ {
+		u := uri + "&per_page=" + convert.IntToString(PerPage) + "&page=" + convert.IntToString(page)
+		rsp, err := wp.get(wp.apiURL(u))
+		if err != nil {
+			return err
+		}
+		wp.Site.Pause()
+		if rsp.Code == 400 && page > 1 {
+			return nil // rest_post_invalid_page_number
+		}
+		if rsp.Error != "" {
+			return errors.Errorf("%s : HTTP %d : %s", u, rsp.Code, rsp.Error)
+		}
+		n, err := decode(rsp.Body)
+		if err != nil {
+			return err
+		}
+		total := convert.ToInt(rsp.Header.Get("X-WP-TotalPages"))
+		if n < PerPage || (total > 0 && page >= total) {
+			return nil
+		}
 	}
 }
 
+// Reasons a post is not mirrored
+const (
+	SkipStatus    = "status"    // Any but publish : future, draft, pending, private
+	SkipProtected = "protected" // Password protected
+	SkipFuture    = "future"    // Scheduled; dated after now
+)
+
+// skipReason returns the reason a post is not to be mirrored, else "".
+// Mirrored are only those published, sans password, and dated not later than now.
+func skipReason(post *Post) string {
+	if post.Status != "" && post.Status != "publish" {
+		return SkipStatus + ":" + post.Status
+	}
+	if post.Content.Protected || post.Excerpt.Protected {
+		return SkipProtected
+	}
+	if t := ToRFC3339(post.DateGMT, 0); !mirror.IsUnixZero(t) && !t.IsZero() && t.After(time.Now()) {
+		return SkipFuture
+	}
+	return ""
+}
+
+// Describe retrieves the metadata of the site; see SiteGot.
+func (wp WP) Describe() {
+	wp.SiteGot()
+}
+
+// Fetch retrieves the posts of the site (see SitePosts) updated, or published (if scheduled), since the checkpoint (since).
+// All are retained regardless if the site mirrors comments (Site.Comments), which may be newer.
+// Those mirrored prior under the legacy identity are aliased thereto (see aliasMirrored).
+func (wp *WP) Fetch(since time.Time) {
+	wp.SitePosts()
+	if n := wp.aliasMirrored(since); n > 0 {
+		log.Printf("INFO : aliases added @ %s : %d (total: %d)\n", wp.Site.HostURL, n, len(wp.Aliases))
+		if err := wp.SaveAliases(); err != nil {
+			log.Printf("ERR : SaveAliases @ %s : %s\n", wp.Site.HostURL, err.Error())
+		}
+	}
+	if since.IsZero() || wp.Site.Comments {
+		return
+	}
+	posts := []Post{}
+	for _, post := range wp.Posts {
+		t := wp.postTime(&post)
+		if mirror.IsZero(t) || t.After(since) || ToRFC3339(post.DateGMT, 0).After(since) {
+			posts = append(posts, post)
+		}
+	}
+	wp.Posts = posts
+}
+
+// Messages maps the fetched posts into Uqrate messages; see PostsToMsgs.
+func (wp WP) Messages() []client.Message {
+	return wp.PostsToMsgs()
+}
+
 // GetTkn retrieves JWT for env.Client.User; get from cache; fetch on miss.
 func (wp WP) GetTkn() string {
 	key := client.CacheKeyTknPrefix + wp.Env.Client.User
@@ -171,9 +432,10 @@ func (wp WP) GetTkn() string {
 }
 
 // getWP retrieves response (JSON) of a WordPress API endpoint; get from cache; fetch on miss.
+// A site of undeclared API mode falls back to the query-string (?rest_route=) form if its /wp-json is blocked.
 func (wp WP) getWP(uri string) (string, error) {
-	url := wp.Site.HostURL + uri
-	key := urlToFname(url)
+	url := wp.apiURL(uri)
+	key := wp.CacheKey(uri)
 
 	// First try cache.
 	bb := wp.Env.GetCache(key)
@@ -181,8 +443,28 @@ func (wp WP) getWP(uri string) (string, error) {
 		//log.Printf("INFO : cache miss @ %s\n", key)
 
 		// Hit the site softly
-		rsp := wp.Env.Get(url, client.JSON)
-		time.Sleep(time.Millisecond * 300)
+		rsp, err := wp.get(url)
+		if err != nil {
+			return "", err
+		}
+		wp.Site.Pause()
+
+		// Per the root probe (SiteURI) only; a 404 of another endpoint is of its object.
+		blocked := rsp.Code == 403 || rsp.Code == 404 || (rsp.Error == "" && !isJSON(rsp.Body))
+		if blocked && wp.Site.API == "" && uri == SiteURI {
+			wp.Site.API = mirror.APIRestRoute
+			url = wp.apiURL(uri)
+			if r, _ := wp.get(url); r != nil && r.Error == "" && isJSON(r.Body) {
+				log.Printf("INFO : /wp-json blocked @ %s : Using ?rest_route=\n", wp.Site.HostURL)
+				rsp = r
+			} else {
+				wp.Site.API = ""
+			}
+			wp.Site.Pause()
+		}
+		if rsp.Error == "" && !isJSON(rsp.Body) {
+			rsp.Error = "response is not JSON"
+		}
 		wp.Site.Status.Object = uri
 		wp.Site.Status.Code = rsp.Code
 
@@ -198,11 +480,32 @@ func (wp WP) getWP(uri string) (string, error) {
 	return convert.BytesToString(bb), nil
 }
 
-// Posts2Msgs denormalizes a WordPress post into a Uqrate message.
+// get performs the GET request of a WordPress API endpoint (url),
+// authenticated per credentials of the site if it declares such (Site.Auth).
+func (wp WP) get(url string) (*client.Response, error) {
+	if wp.Site.Auth == "" {
+		return wp.Env.Get(url, client.JSON), nil
+	}
+	user, pass, err := mirror.Credentials(wp.Env.NS, wp.Site.Auth)
+	if err != nil {
+		return nil, err
+	}
+	return wp.Env.GetByBasic(url, client.JSON, user, pass), nil
+}
+
+// Posts2Msgs denormalizes a WordPress post into a Uqrate message,
+// followed by replies thereto per its comments if the site so declares (Site.Comments).
 func (wp WP) PostsToMsgs() []client.Message {
 	list := []client.Message{}
-	for _, post := range wp.Site.Posts {
-		list = append(list, wp.PostToMsg(&post))
+	for _, post := range wp.Posts {
+		msg := wp.PostToMsg(&post)
+		if msg.ID == "" {
+			continue
+		}
+		list = append(list, msg)
+		if wp.Site.Comments {
+			list = append(list, wp.CommentsToMsgs(&msg, &post)...)
+		}
 	}
 	return list
 }
@@ -210,14 +513,19 @@ func (wp WP) PostsToMsgs() []client.Message {
 // Posts2Msgs denormalizes a *Post into a client.Message,
 // retrieving the various WordPress objects (referenced at Post subkeys)
 // as needed to populate Message keys (.Cats, .Tags).
-// Message.ID is a static UUID (v5) per Message.ChnID namespace and Message.URI name.
+// Message.ID is a static UUID (v5) per Message.ChnID namespace and Message.URI name,
+// else per that of the site's declared identity strategy (see identityKey).
 func (wp WP) PostToMsg(post *Post) client.Message {
 	msg := client.Message{}
 
 	msg.ChnID = wp.Site.ChnID
-	msg.URI = linkToURI(post.Link)
+	var err error
+	if msg.URI, err = mirror.LinkToURI(post.Link, wp.Site.HostURL); err != nil {
+		log.Printf("ERR : LinkToURI : post %d : %s\n", post.ID, err.Error())
+		return client.Message{}
+	}
 	//msg.ID = uuid.NewV5(uuid.Must(uuid.FromString(msg.ChnID)), strings.ToLower(msg.URI)).String()
-	msg.ID, _ = id.UUIDv5(msg.ChnID, msg.URI)
+	msg.ID = wp.msgID(post, msg.URI)
 	if msg.ID == "" {
 		log.Printf("ERR : UUIDv5 fail : URI: %s .\n", msg.URI)
 		return client.Message{URI: msg.URI}
@@ -226,9 +534,17 @@ func (wp WP) PostToMsg(post *Post) client.Message {
 	msg.Title = post.Title.Rendered
 	msg.Body = post.Content.Rendered
 
-	if post.Excerpt.Rendered != "" {
-		msg.Summary = post.Excerpt.Rendered
+	// Prepend the featured image unless the body already renders it (any size thereof).
+	if m := wp.featuredMedia(post); m != nil {
+		if !mirror.Renders(msg.Body, m.SourceURL) {
+			msg.Body = figure(m) + msg.Body
+		}
 	}
+	if wp.Cleaner != nil {
+		msg.Body = wp.Cleaner(msg.Body)
+	}
+
+	msg.Summary = wp.Summary(post.Excerpt.Rendered, post.Content.Rendered)
 
 	if true {
 		if len(post.Categories) > 0 {
@@ -240,42 +556,33 @@ func (wp WP) PostToMsg(post *Post) client.Message {
 	}
 	// Add the author's name to the list of tags for this message.
 	uri := appendToURL(AuthorsURI, convert.IntToString(post.Author))
-	if author := wp.objName(uri); author != "" {
-		if !strings.Contains(author, "s") {
-			msg.Tags = append(msg.Tags, author)
-		}
-	}
+	msg.Tags = mirror.AuthorTag(msg.Tags, wp.objName(uri))
 
-	sanitize(msg.Cats)
-	sanitize(msg.Tags)
+	mirror.Sanitize(msg.Cats)
+	mirror.Sanitize(msg.Tags)
 
 	// Recover the post timestamp
 
-	off := wp.Site.GMTOffset
-	msg.DateUpdate = ToRFC3339(post.ModifiedGMT, 0)
-	if IsUnixZero(msg.DateUpdate) {
-		msg.DateUpdate = ToRFC3339(post.Modified, off)
-	}
-	if IsUnixZero(msg.DateUpdate) {
-		msg.DateUpdate = ToRFC3339(post.DateGMT, 0)
-	}
-	if IsUnixZero(msg.DateUpdate) {
-		msg.DateUpdate = ToRFC3339(post.Date, off)
-	}
-	if IsUnixZero(msg.DateUpdate) || msg.DateUpdate.IsZero() {
-		msg.DateUpdate = time.Now().Truncate(1 * time.Second).UTC()
-		log.Printf("WARN : msg.DateUpdate : NOT FOUND : Set to current time.\n")
-	}
-	log.Printf("INFO : msg.DateUpdate : %v : GMT Offset was: %d\n", msg.DateUpdate, off)
+	msg.DateUpdate = mirror.DateUpdate(wp.postTime(post))
+	log.Printf("INFO : msg.DateUpdate : %v : Location was: %s\n", msg.DateUpdate, wp.Site.Location())
 
 	return msg
 }
 
-// sanitize each name of list.
-func sanitize(names []string) {
-	for i, name := range names {
-		names[i] = str.CleanAlphaNum(name, 35)
+// postTime recovers the (GMT) time a post was last modified, else created; zero if neither.
+func (wp WP) postTime(post *Post) time.Time {
+	loc := wp.Site.Location()
+	t := ToRFC3339(post.ModifiedGMT, 0)
+	if mirror.IsZero(t) {
+		t = ToRFC3339In(post.Modified, loc)
+	}
+	if mirror.IsZero(t) {
+		t = ToRFC3339(post.DateGMT, 0)
 	}
+	if mirror.IsZero(t) {
+		t = ToRFC3339In(post.Date, loc)
+	}
+	return t
 }
 
 type object struct {
@@ -371,25 +678,29 @@ func appendToURL(url, a string) string {
 	return url
 }
 
-// urlToFname converts url to rtn (cache fname), e.g.,
+// routeToFname converts the route (uri) of a WordPress API endpoint of a site (domain) to rtn (cache fname);
+// per the route sans "/wp-json" (and "/wp/v2"), so regardless of the API root thereof (see apiURL), e.g.,
 //
-//	url : "https://TheWpSite.com/wp-json/wp/v2/posts?author=7"
+//	uri : "/wp-json/wp/v2/posts?author=7"
 //	rtn : "TheWpSite.com_posts.json"
-//	url : "https://TheWpSite.com/wp-json/wp/v2/users/7"
+//	uri : "/wp-json/wp/v2/users/7"
 //	rtn : "TheWpSite.com_users.7.json"
-func urlToFname(url string) string {
-	site := fqdn(url)
-	var obj, fname string
-	if strings.Contains(url, "wp-json/wp/v2") {
-		obj = strings.Split(url, "wp-json/wp/v2")[1]
-	} else {
-		if strings.Contains(url, "wp-json/") {
-			obj = strings.Split(url, "wp-json/")[1]
+//	uri : "/wp-json/wp/v2/comments?_fields=id&post=12"
+//	rtn : "TheWpSite.com_comments.post.12.json"
+//	uri : "/wp-json/?_fields=name"
+//	rtn : "TheWpSite.com.json"
+func routeToFname(site, uri string) string {
+	var fname string
+	ss := strings.SplitN(uri, "?", 2)
+	obj := strings.TrimPrefix(strings.TrimPrefix(ss[0], "/wp-json"), "/wp/v2")
+	if len(ss) > 1 && strings.Contains(obj, "comments") {
+		// Key per post; comments thereof are fetched per query.
+		if q, err := neturl.ParseQuery(ss[1]); err == nil && q.Get("post") != "" {
+			obj += "/post/" + q.Get("post")
 		}
 	}
-	obj = strings.Split(obj, "?")[0]
-	ss := strings.Split(obj, "/")
-	if len(ss) > 1 {
+	ss = strings.Split(obj, "/")
+	if len(ss) > 1 && strings.Trim(obj, "/") != "" {
 		fname = site + "_"
 	} else {
 		fname = site + "."
@@ -414,15 +725,9 @@ func fqdn(url string) string {
 	return ss[0]
 }
 
-// "https://foo.bar.baz/a/b" to "/a/b"
-func linkToURI(link string) string {
-	x := strings.SplitAfter(link, "://")
-	x = strings.SplitAfter(x[1], "/")
-	return "/" + strings.Join(x[1:], "")
-}
-
 // ToRFC3339 parses WordPress-posts ($post) date into Golang time (GMT).
-func ToRFC3339(date string, offset int) time.Time {
+// The offset (hours) may be fractional, e.g., 5.5 (+05:30) or -3.5 (-03:30).
+func ToRFC3339(date string, offset float64) time.Time {
 	// ToRFC3339("2021-12-21T13:15:31", -6)
 	//==>         2021-12-21 19:15:31 +0000 UTC
 	// WP REST API @ /wp-json :
@@ -433,21 +738,26 @@ func ToRFC3339(date string, offset int) time.Time {
 	// "modified":     "2022-01-11T14:22:07",
 	// "modified_gmt": "2022-01-11T20:22:07",
 	off := "Z"
-	if offset < 0 {
-		if offset > -26 {
-			off = fmt.Sprintf("%.2d:00", offset)
-		}
-	} else {
-		if offset < 26 {
-			off = fmt.Sprintf("+%.2d:00", offset)
+	if offset > -26 && offset < 26 && offset != 0 {
+		sign := "+"
+		if offset < 0 {
+			sign = "-"
 		}
+		mins := int(math.Round(math.Abs(offset) * 60))
+		off = fmt.Sprintf("%s%.2d:%.2d", sign, mins/60, mins%60)
 	}
 	t, _ := time.Parse(time.RFC3339, (date + off))
 	return t.Truncate(1 * time.Second).UTC()
 }
 
-// IsUnixZero tests for "1970-01-01 00:00:00 +0000 UTC".
-// Unlike time pkg t.IsZero(), which tests for "0001-01-01 00:00:00 +0000 UTC".
-func IsUnixZero(t time.Time) bool {
-	return t == time.Unix(0, 0).UTC()
+// ToRFC3339In parses WordPress-posts ($post) local date, per its location (loc), into Golang time (GMT).
+// Unlike a fixed offset, the location accounts for daylight saving time (DST) at that date.
+func ToRFC3339In(date string, loc *time.Location) time.Time {
+	// ToRFC3339In("2022-07-11T14:22:07", "America/New_York")
+	//==>          2022-07-11 18:22:07 +0000 UTC
+	if loc == nil {
+		loc = time.UTC
+	}
+	t, _ := time.ParseInLocation("2006-01-02T15:04:05", date, loc)
+	return t.Truncate(1 * time.Second).UTC()
 }
@@ -0,0 +1,48 @@
+package wordpress
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // Zones regardless of host
+)
+
+func TestToRFC3339(t *testing.T) {
+	tests := []struct {
+		date   string
+		offset float64
+		want   string
+	}{
+		{"2021-12-21T13:15:31", -6, "2021-12-21T19:15:31Z"},
+		{"2021-12-21T13:15:31", 0, "2021-12-21T13:15:31Z"},
+		{"2021-12-21T13:15:31", 5.5, "2021-12-21T07:45:31Z"},
+		{"2021-12-21T13:15:31", -3.5, "2021-12-21T16:45:31Z"},
+		{"2021-12-21T13:15:31", 5.75, "2021-12-21T07:30:31Z"},
+	}
+	for _, tt := range tests {
+		if got := ToRFC3339(tt.date, tt.offset).Format(time.RFC3339); got != tt.want {
+			t.Errorf("ToRFC3339(%q, %v) = %s, want %s", tt.date, tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestToRFC3339In(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		date string
+		loc  *time.Location
+		want string
+	}{
+		{"2022-07-11T14:22:07", ny, "2022-07-11T18:22:07Z"}, // EDT
+		{"2022-01-11T14:22:07", ny, "2022-01-11T19:22:07Z"}, // EST
+		{"2022-01-11T14:22:07", nil, "2022-01-11T14:22:07Z"},
+		{"2022-01-11T14:22:07", time.FixedZone("", 19800), "2022-01-11T08:52:07Z"},
+	}
+	for _, tt := range tests {
+		if got := ToRFC3339In(tt.date, tt.loc).Format(time.RFC3339); got != tt.want {
+			t.Errorf("ToRFC3339In(%q, %v) = %s, want %s", tt.date, tt.loc, got, tt.want)
+		}
+	}
+}
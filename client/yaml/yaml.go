@@ -0,0 +1,314 @@
+// Package yaml decodes and encodes the subset of YAML of the sites list and of the front matter of Markdown files.
+package yaml
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrEmpty is that of a document of no content; blank, else only comments.
+var ErrEmpty = errors.New("empty document")
+
+// yline is a (significant) line of a YAML document.
+type yline struct {
+	n      int // Line number
+	indent int
+	text   string // Sans indent and comment
+}
+
+// yparser parses the subset of YAML of (block) mappings and sequences of any nesting,
+// whose scalars are plain or quoted, and inline sequences ([a, b]) thereof;
+// block scalars (| and >) are of mapping values only.
+// Scalars decode as string; anchors, tags, flow mappings and multi-line plain scalars are not supported.
+type yparser struct {
+	src   []string // All lines
+	lines []yline
+	i     int
+}
+
+// Parse decodes a YAML document into map[string]interface{}, []interface{} and string values.
+func Parse(src string) (interface{}, error) {
+	p := &yparser{src: strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")}
+	for n, line := range p.src {
+		text := strings.TrimRight(comment(line), " \t")
+		t := strings.TrimLeft(text, " ")
+		if t == "" || t == "---" {
+			continue
+		}
+		if strings.HasPrefix(t, "\t") {
+			return nil, errors.Errorf("line %d : tab indentation", n+1)
+		}
+		p.lines = append(p.lines, yline{n: n + 1, indent: len(text) - len(t), text: t})
+	}
+	if len(p.lines) == 0 {
+		return nil, ErrEmpty
+	}
+	v, err := p.node(p.lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.i < len(p.lines) {
+		return nil, errors.Errorf("line %d : unexpected indentation", p.lines[p.i].n)
+	}
+	return v, nil
+}
+
+// node parses the mapping or sequence at indent.
+func (p *yparser) node(indent int) (interface{}, error) {
+	if isItem(p.lines[p.i].text) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+func (p *yparser) sequence(indent int) ([]interface{}, error) {
+	vv := []interface{}{}
+	for p.i < len(p.lines) {
+		l := p.lines[p.i]
+		if l.indent < indent || !isItem(l.text) {
+			break
+		}
+		if l.indent > indent {
+			return nil, errors.Errorf("line %d : unexpected indentation", l.n)
+		}
+		rest := strings.TrimLeft(strings.TrimPrefix(l.text, "-"), " ")
+		switch {
+		case rest == "":
+			p.i++
+			if p.i >= len(p.lines) || p.lines[p.i].indent <= indent {
+				vv = append(vv, "")
+				continue
+			}
+			v, err := p.node(p.lines[p.i].indent)
+			if err != nil {
+				return nil, err
+			}
+			vv = append(vv, v)
+		case isKey(rest):
+			// A mapping, its first key inline with the item; the rest indented thereto.
+			p.lines[p.i] = yline{n: l.n, indent: l.indent + len(l.text) - len(rest), text: rest}
+			v, err := p.mapping(p.lines[p.i].indent)
+			if err != nil {
+				return nil, err
+			}
+			vv = append(vv, v)
+		default:
+			p.i++
+			vv = append(vv, value(rest))
+		}
+	}
+	return vv, nil
+}
+
+func (p *yparser) mapping(indent int) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	for p.i < len(p.lines) {
+		l := p.lines[p.i]
+		if l.indent < indent || isItem(l.text) {
+			break
+		}
+		if l.indent > indent {
+			return nil, errors.Errorf("line %d : unexpected indentation", l.n)
+		}
+		k, v, ok := splitKey(l.text)
+		if !ok {
+			return nil, errors.Errorf("line %d : malformed mapping : %s", l.n, l.text)
+		}
+		p.i++
+		if isBlock(v) {
+			m[k] = p.block(l.n, indent, v)
+			continue
+		}
+		if v != "" {
+			m[k] = value(v)
+			continue
+		}
+		// Nested per indent, else a sequence of the same indent.
+		if p.i < len(p.lines) {
+			next := p.lines[p.i]
+			if next.indent > indent || (next.indent == indent && isItem(next.text)) {
+				child, err := p.node(next.indent)
+				if err != nil {
+					return nil, err
+				}
+				m[k] = child
+				continue
+			}
+		}
+		m[k] = nil
+	}
+	return m, nil
+}
+
+// block returns the block scalar of header (| or >, and chomping indicator) of the mapping at line (n) of indent;
+// the lines following thereof, blank or indented beyond, per their indent of the first.
+// Literal (|) lines are kept as are; folded (>) are joined by space, blank lines as newlines.
+func (p *yparser) block(n, indent int, header string) string {
+	ss := []string{}
+	for _, line := range p.src[n:] {
+		t := strings.TrimLeft(line, " ")
+		if t != "" && len(line)-len(t) <= indent {
+			break
+		}
+		ss = append(ss, strings.TrimRight(line, " \t"))
+	}
+	for p.i < len(p.lines) && p.lines[p.i].n <= n+len(ss) {
+		p.i++
+	}
+	pad := -1
+	for _, s := range ss {
+		if t := strings.TrimLeft(s, " "); t != "" {
+			pad = len(s) - len(t)
+			break
+		}
+	}
+	var b strings.Builder
+	for i, s := range ss {
+		if pad > -1 && len(s) >= pad {
+			s = s[pad:]
+		} else {
+			s = ""
+		}
+		switch {
+		case i == 0:
+		case header[0] == '|', s == "":
+			b.WriteString("\n")
+		case strings.TrimSpace(ss[i-1]) == "":
+			// Folded per the newline of the blank line.
+		default:
+			b.WriteString(" ")
+		}
+		b.WriteString(s)
+	}
+	text := strings.TrimRight(b.String(), "\n")
+	switch {
+	case strings.HasSuffix(header, "-"), text == "":
+		return text
+	case strings.HasSuffix(header, "+"):
+		return text + strings.Repeat("\n", len(b.String())-len(text)+1)
+	}
+	return text + "\n"
+}
+
+// isBlock reports whether the value of a mapping is the header of a block scalar.
+func isBlock(v string) bool {
+	switch v {
+	case "|", ">", "|-", ">-", "|+", ">+":
+		return true
+	}
+	return false
+}
+
+func isItem(t string) bool {
+	return t == "-" || strings.HasPrefix(t, "- ")
+}
+
+func isKey(t string) bool {
+	_, _, ok := splitKey(t)
+	return ok
+}
+
+// splitKey splits "key: value" (or "key:") into its key and value.
+func splitKey(t string) (string, string, bool) {
+	if t == "" || t[0] == '"' || t[0] == '\'' || t[0] == '[' {
+		return "", "", false
+	}
+	i := strings.Index(t, ": ")
+	if i < 0 {
+		if !strings.HasSuffix(t, ":") {
+			return "", "", false
+		}
+		i = len(t) - 1
+	}
+	k := strings.TrimSpace(t[:i])
+	if k == "" {
+		return "", "", false
+	}
+	return k, strings.TrimSpace(t[i+1:]), true
+}
+
+// value decodes a scalar, else an inline sequence.
+func value(v string) interface{} {
+	if strings.HasPrefix(v, "[") && strings.HasSuffix(v, "]") {
+		vv := []interface{}{}
+		for _, s := range splitList(v[1 : len(v)-1]) {
+			if s = strings.TrimSpace(s); s != "" {
+				vv = append(vv, unquote(s))
+			}
+		}
+		return vv
+	}
+	return unquote(v)
+}
+
+// splitList splits the elements of an inline sequence; on commas not quoted.
+func splitList(v string) []string {
+	var (
+		ss    []string
+		quote rune
+		cur   strings.Builder
+	)
+	for _, r := range v {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ',':
+			ss = append(ss, cur.String())
+			cur.Reset()
+			continue
+		}
+		cur.WriteRune(r)
+	}
+	return append(ss, cur.String())
+}
+
+func unquote(s string) string {
+	if len(s) > 1 {
+		switch {
+		case s[0] == '"' && s[len(s)-1] == '"':
+			if u, err := strconv.Unquote(s); err == nil {
+				return u
+			}
+			return s[1 : len(s)-1]
+		case s[0] == '\'' && s[len(s)-1] == '\'':
+			return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+		}
+	}
+	return s
+}
+
+// Quote renders a scalar as such, quoted if it would otherwise not decode as is.
+func Quote(s string) string {
+	if s == "" || s != strings.TrimSpace(s) || strings.ContainsAny(s, "\"'#[],\\") ||
+		strings.Contains(s, ": ") || strings.HasSuffix(s, ":") || strings.HasPrefix(s, "- ") || s == "-" {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// comment removes the comment of a line; that of a " #" (or leading "#") not quoted.
+func comment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || line[i-1] == ' ' || line[i-1] == '[' || line[i-1] == ',' {
+				quote = c
+			}
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,85 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+type (
+	m = map[string]interface{}
+	l = []interface{}
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name, src string
+		want      interface{}
+	}{
+		{"mapping", "---\na: 1\nb: \"two # not comment\" # comment\nc: 'it''s'\nd:\ne: x: y\n",
+			m{"a": "1", "b": "two # not comment", "c": "it's", "d": nil, "e": "x: y"}},
+		{"nested", "a:\n  b:\n    c: x\n  d: y\nz: w",
+			m{"a": m{"b": m{"c": "x"}, "d": "y"}, "z": "w"}},
+		{"sequence", "- a\n- \"b\"\n-\n- [c, 'd, e', \"f\"]",
+			l{"a", "b", "", l{"c", "d, e", "f"}}},
+		{"sequence of mappings", "list:\n- a: 1\n  b: 2\n-\n  c: 3\n-\n  - x\n  - y",
+			m{"list": l{m{"a": "1", "b": "2"}, m{"c": "3"}, l{"x", "y"}}}},
+		{"crlf and comments", "# head\r\na: b\r\n\r\n  # indented\r\nc: [] # none\r\n",
+			m{"a": "b", "c": l{}}},
+		{"literal", "a: |\n  one\n    two\n\n  three\n\nb: x",
+			m{"a": "one\n  two\n\nthree\n", "b": "x"}},
+		{"folded", "a: >-\n  one\n  two\n\n  three\n  # not comment\nb: x",
+			m{"a": "one two\nthree # not comment", "b": "x"}},
+		{"keep", "a: |+\n  one\n\nb: x",
+			m{"a": "one\n\n", "b": "x"}},
+		{"empty block", "a: >\nb: x",
+			m{"a": "", "b": "x"}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.src)
+		if err != nil {
+			t.Errorf("%s : %s", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s\n got: %#v\nwant: %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{
+		"a: b\n\tc: d",
+		"a: b\n  c: d",
+		"a:\n  - x\n   - y",
+		"not a pair",
+	} {
+		if _, err := Parse(src); err == nil {
+			t.Errorf("Parse(%q) : want error", src)
+		}
+	}
+	for _, src := range []string{"", "\n---\n", "# only\n  # comments"} {
+		if _, err := Parse(src); err != ErrEmpty {
+			t.Errorf("Parse(%q) : %v, want ErrEmpty", src, err)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	for _, s := range []string{
+		"plain", "", " padded ", "a: b", "key:", "- item", "-", "#tag", "a # b", "[x]", "a, b",
+		`say "hi"`, "it's", `back\slash`, "https://x.com/?a=1&b=2", "café",
+	} {
+		q := Quote(s)
+		got, err := Parse("k: " + q)
+		if err != nil {
+			t.Errorf("Quote(%q) = %s : %s", s, q, err)
+			continue
+		}
+		if v := got.(m)["k"]; v != s && !(s == "" && v == nil) {
+			t.Errorf("Quote(%q) = %s, decodes as %q", s, q, v)
+		}
+	}
+	if Quote("plain") != "plain" || Quote("a: b") == "a: b" {
+		t.Error("Quote : quoting only as needed")
+	}
+}
@@ -8,6 +8,7 @@ require (
 	github.com/imroc/req/v3 v3.9.3
 	github.com/pkg/errors v0.9.1
 	github.com/sempernow/kit v0.8.2
+	golang.org/x/net v0.0.0-20220225172249-27dd8689420f
 )
 
 require (
@@ -15,7 +16,6 @@ require (
 	github.com/hashicorp/go-multierror v1.1.1 // indirect
 	github.com/klauspost/cpuid/v2 v2.0.11 // indirect
 	github.com/oklog/ulid v1.3.1 // indirect
-	golang.org/x/net v0.0.0-20220225172249-27dd8689420f // indirect
 	golang.org/x/text v0.3.7 // indirect
 	lukechampine.com/blake3 v1.1.7 // indirect
 )